@@ -1,33 +1,76 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/AOShei/pdf-loader/pkg/loader"
+	"github.com/AOShei/pdf-loader/pkg/model"
 )
 
+// maxPasswordPrompts bounds how many times promptPassword will ask on
+// stderr before giving up and letting the wrong-password error surface.
+const maxPasswordPrompts = 3
+
+// promptPassword is a loader.LoadOptions.PasswordCallback that asks for a
+// password on stderr and reads it from stdin. It's only invoked when the
+// password already tried (the --password flag, or the empty default)
+// didn't validate.
+func promptPassword(attempt int) (string, bool) {
+	if attempt >= maxPasswordPrompts {
+		return "", false
+	}
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
 func main() {
 	concurrent := flag.Bool("concurrent", false, "Enable concurrent page processing")
 	workers := flag.Int("workers", 0, "Number of worker threads (0 = auto-detect, default: NumCPU)")
 	extractImages := flag.Bool("images", false, "Extract image metadata (width, height, position) from pages")
+	password := flag.String("password", "", "User or owner password for an encrypted PDF")
+	verifySignatures := flag.Bool("verify-signatures", false, "Verify embedded PKCS#7 signatures; exit non-zero if any are invalid")
+	ndjson := flag.Bool("ndjson", false, "Stream NDJSON instead of one JSON document: a {\"type\":\"metadata\"} record, then one {\"type\":\"page\"} record per page")
 	flag.Parse()
 
 	if flag.NArg() < 1 {
-		log.Fatal("Usage: pdf-loader [--concurrent] [--workers N] [--images] <path_to_pdf>")
+		log.Fatal("Usage: pdf-loader [--concurrent] [--workers N] [--images] [--password PW] [--verify-signatures] [--ndjson] <path_to_pdf>")
 	}
 
 	path := flag.Arg(0)
 
+	opts := loader.LoadOptions{
+		Password:         *password,
+		PasswordCallback: promptPassword,
+		VerifySignatures: *verifySignatures,
+		ExtractImages:    *extractImages,
+	}
+	if *concurrent {
+		opts.Workers = *workers
+	} else {
+		opts.Workers = 1
+	}
+
+	if *ndjson {
+		os.Exit(runNDJSON(path, opts))
+	}
+
 	var err error
-	var doc any
+	var doc *model.Document
 
 	if *concurrent {
-		doc, err = loader.LoadPDFConcurrent(path, *workers, *extractImages)
+		doc, err = loader.LoadPDFConcurrentWithOptions(path, opts, *workers, *extractImages)
 	} else {
-		doc, err = loader.LoadPDF(path, *extractImages)
+		doc, err = loader.LoadPDFWithOptions(path, opts, *extractImages)
 	}
 
 	if err != nil {
@@ -41,4 +84,57 @@ func main() {
 	if err := encoder.Encode(doc); err != nil {
 		log.Fatalf("Failed to encode JSON: %v", err)
 	}
+
+	if *verifySignatures {
+		for _, sig := range doc.Metadata.Signatures {
+			if !sig.Verified {
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// metadataRecord and pageRecord embed model.Metadata/model.Page so their
+// fields are promoted straight into the NDJSON line alongside "type",
+// giving e.g. {"type":"metadata","title":"...", ...}.
+type metadataRecord struct {
+	Type string `json:"type"`
+	model.Metadata
+}
+
+type pageRecord struct {
+	Type string `json:"type"`
+	model.Page
+}
+
+// runNDJSON streams path as NDJSON: a metadata record as soon as it's
+// known (via opts.OnMetadata, which StreamPDF calls before any page is
+// extracted), then one page record per page in page order. It returns the
+// process exit code for main to pass to os.Exit: 1 when any signature
+// fails verification (the same check the non-NDJSON path makes for
+// --verify-signatures), else 0. Load and encode errors still end the
+// process through log.Fatalf.
+func runNDJSON(path string, opts loader.LoadOptions) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+
+	opts.OnMetadata = func(meta model.Metadata) {
+		if err := encoder.Encode(metadataRecord{Type: "metadata", Metadata: meta}); err != nil {
+			log.Fatalf("Failed to encode metadata record: %v", err)
+		}
+	}
+
+	meta, err := loader.StreamPDF(path, opts, func(page model.Page) error {
+		return encoder.Encode(pageRecord{Type: "page", Page: page})
+	})
+	if err != nil {
+		log.Fatalf("Failed to stream PDF: %v", err)
+	}
+
+	for _, sig := range meta.Signatures {
+		if !sig.Verified {
+			return 1
+		}
+	}
+	return 0
 }
@@ -1,307 +1,203 @@
 package loader
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"runtime"
-	"sync"
-	"time"
 
 	"github.com/AOShei/pdf-loader/pkg/model"
 	"github.com/AOShei/pdf-loader/pkg/pdf"
 )
 
-// pageResult holds the result of processing a single page
-type pageResult struct {
-	pageNum int
-	page    model.Page
-	err     error
+// LoadOptions configures how a PDF is authenticated and extracted.
+// Password is tried first; if it's rejected with pdf.ErrWrongPassword and
+// PasswordCallback is set, the callback is invoked with an increasing
+// attempt counter (starting at 0) to obtain another password to try.
+// Returning ok=false from the callback, or leaving it nil, surfaces the
+// wrong-password error to the caller instead of retrying.
+type LoadOptions struct {
+	Password         string
+	PasswordCallback func(attempt int) (string, bool)
+	// VerifySignatures, when true, walks the document's AcroForm for
+	// embedded PKCS#7 signatures and populates Metadata.Signatures. This is
+	// opt-in because it means reading extra file bytes and doing public-key
+	// crypto per signature, on top of the normal extraction work.
+	VerifySignatures bool
+	// ExtractImages mirrors the extractImages parameter every LoadPDF*
+	// function already takes; StreamPDF has no separate parameter for it,
+	// so it reads this field instead.
+	ExtractImages bool
+	// Workers controls how many goroutines StreamPDF uses to extract pages
+	// concurrently (0 = runtime.NumCPU()). LoadPDF/LoadPDFFromReader pin
+	// this to 1; LoadPDFConcurrent/LoadPDFConcurrentFromReader set it from
+	// their own workers parameter.
+	Workers int
+	// OnMetadata, if set, is called with the document's Metadata as soon as
+	// it's known - before any page has been extracted. StreamPDF's own
+	// Metadata return value only becomes available once every page has
+	// streamed through emit, so callers that need metadata first (such as
+	// NDJSON output, where the metadata record must be the first line) use
+	// this instead of waiting on the return value.
+	OnMetadata func(model.Metadata)
 }
 
-// LoadPDF takes a file path and returns the structured Document.
-func LoadPDF(path string, extractImages bool) (*model.Document, error) {
-	// 1. Open File
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	// 2. Initialize the Low-Level Reader
-	reader, err := pdf.NewReader(f)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create pdf reader: %w", err)
+// authenticate builds a *pdf.Reader over ra, trying opts.Password and then,
+// via opts.PasswordCallback, further passwords until one validates or the
+// callback gives up. ra/size are reused verbatim across retries - unlike
+// the old file-handle-based reader, pdf.Reader is built over an
+// io.ReaderAt, so there's no seek position to reset between attempts.
+func authenticate(ra io.ReaderAt, size int64, opts LoadOptions) (*pdf.Reader, error) {
+	password := opts.Password
+	for attempt := 0; ; attempt++ {
+		reader, err := pdf.NewReaderWithPassword(ra, size, password)
+		if err == nil {
+			return reader, nil
+		}
+		if !errors.Is(err, pdf.ErrWrongPassword) || opts.PasswordCallback == nil {
+			return nil, fmt.Errorf("failed to create pdf reader: %w", err)
+		}
+		next, ok := opts.PasswordCallback(attempt)
+		if !ok {
+			return nil, fmt.Errorf("failed to create pdf reader: %w", err)
+		}
+		password = next
 	}
+}
 
-	// 3. Extract Metadata
+// buildMetadata extracts Metadata from an already-authenticated reader,
+// including signature verification when opts.VerifySignatures is set.
+func buildMetadata(reader *pdf.Reader, opts LoadOptions) model.Metadata {
 	meta := model.Metadata{
-		Encrypted: reader.IsEncrypted(),
+		Encrypted:  reader.IsEncrypted(),
+		Linearized: reader.IsLinearized(),
 	}
 
 	// Skip metadata extraction if encrypted (strings will be garbage)
 	if !meta.Encrypted {
 		if info, err := reader.GetInfo(); err == nil && info != nil {
-			if t, ok := info["/Title"].(pdf.StringObject); ok {
-				meta.Title = string(t)
+			v := reader.Value(info)
+			if t, ok := v.Key("/Title").String(); ok {
+				meta.Title = t
 			}
-			if a, ok := info["/Author"].(pdf.StringObject); ok {
-				meta.Author = string(a)
+			if a, ok := v.Key("/Author").String(); ok {
+				meta.Author = a
 			}
-			if c, ok := info["/Creator"].(pdf.StringObject); ok {
-				meta.Creator = string(c)
+			if c, ok := v.Key("/Creator").String(); ok {
+				meta.Creator = c
 			}
-			if p, ok := info["/Producer"].(pdf.StringObject); ok {
-				meta.Producer = string(p)
+			if p, ok := v.Key("/Producer").String(); ok {
+				meta.Producer = p
 			}
 		}
 	}
 
-	// Log if encrypted (attempting decryption with empty password)
-	if meta.Encrypted {
-		fmt.Fprintf(os.Stderr, "PDF is encrypted. Attempting to decrypt with empty password (owner-password-only PDFs)...\n")
-	}
-
-	doc := &model.Document{
-		Metadata: meta,
-		Pages:    make([]model.Page, 0, reader.NumPages()),
-	}
-
-	// 4. Iterate Pages and Extract Text
-	numPages := reader.NumPages()
-	fmt.Fprintf(os.Stderr, "Processing %d pages...\n", numPages)
-
-	for i := 0; i < numPages; i++ {
-		start := time.Now()
-
-		// Get Page Dictionary
-		pdfPage, err := reader.GetPage(i)
+	if opts.VerifySignatures {
+		sigs, err := reader.VerifySignatures(nil)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting page %d: %v\n", i+1, err)
-			continue
-		}
-
-		// Initialize Extractor for this page
-		extractor, err := pdf.NewExtractor(reader, pdfPage, extractImages)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating extractor for page %d: %v\n", i+1, err)
-			continue
-		}
-
-		// Extract!
-		text, err := extractor.ExtractText()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error extracting text from page %d: %v\n", i+1, err)
-			continue
+			fmt.Fprintf(os.Stderr, "Error verifying signatures: %v\n", err)
+		} else {
+			meta.Signatures = sigs
 		}
+	}
 
-		// Basic dimensions (MediaBox)
-		var width, height float64
-		if mBox, ok := pdfPage["/MediaBox"].(pdf.ArrayObject); ok && len(mBox) == 4 {
-			// [x1 y1 x2 y2] -> width = x2-x1, height = y2-y1
-			// Simplified: assume x1,y1 are 0
-			if w, ok := mBox[2].(pdf.NumberObject); ok {
-				width = float64(w)
-			}
-			if h, ok := mBox[3].(pdf.NumberObject); ok {
-				height = float64(h)
-			}
-		}
+	// Log if encrypted (authentication already succeeded by this point, or
+	// NewReaderWithPassword would have returned an error above)
+	if meta.Encrypted {
+		fmt.Fprintf(os.Stderr, "PDF is encrypted; authenticated successfully.\n")
+	}
 
-		doc.Pages = append(doc.Pages, model.Page{
-			PageNumber: i + 1,
-			Content:    text,
-			CharCount:  len(text),
-			Width:      width,
-			Height:     height,
-			Images:     extractor.GetImages(),
-		})
+	return meta
+}
 
-		fmt.Fprintf(os.Stderr, "Page %d processed in %v (%d chars)\n", i+1, time.Since(start), len(text))
-	}
+// LoadPDF takes a file path and returns the structured Document, authenticating
+// with the empty password (the common case for unencrypted or
+// owner-password-only PDFs).
+func LoadPDF(path string, extractImages bool) (*model.Document, error) {
+	return LoadPDFWithOptions(path, LoadOptions{}, extractImages)
+}
 
-	return doc, nil
+// LoadPDFWithPassword is LoadPDF, but authenticates with password instead of
+// always trying the empty one. The password is tried as both the user and
+// owner password - see pdf.NewReaderWithPassword.
+func LoadPDFWithPassword(path, password string, extractImages bool) (*model.Document, error) {
+	return LoadPDFWithOptions(path, LoadOptions{Password: password}, extractImages)
 }
 
-// LoadPDFConcurrent loads a PDF and extracts text using concurrent page processing.
-// The workers parameter specifies the number of concurrent workers (0 = auto-detect using NumCPU).
-func LoadPDFConcurrent(path string, workers int, extractImages bool) (*model.Document, error) {
-	// 1. Open File to get metadata and page count
+// LoadPDFWithOptions is LoadPDF, generalized to LoadOptions's retry-capable
+// authentication (a fixed password plus an optional PasswordCallback for
+// interactive retries).
+func LoadPDFWithOptions(path string, opts LoadOptions, extractImages bool) (*model.Document, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	// 2. Initialize the Low-Level Reader
-	reader, err := pdf.NewReader(f)
+	info, err := f.Stat()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pdf reader: %w", err)
-	}
-
-	// 3. Extract Metadata
-	meta := model.Metadata{
-		Encrypted: reader.IsEncrypted(),
-	}
-
-	if !meta.Encrypted {
-		if info, err := reader.GetInfo(); err == nil && info != nil {
-			if t, ok := info["/Title"].(pdf.StringObject); ok {
-				meta.Title = string(t)
-			}
-			if a, ok := info["/Author"].(pdf.StringObject); ok {
-				meta.Author = string(a)
-			}
-			if c, ok := info["/Creator"].(pdf.StringObject); ok {
-				meta.Creator = string(c)
-			}
-			if p, ok := info["/Producer"].(pdf.StringObject); ok {
-				meta.Producer = string(p)
-			}
-		}
+		return nil, err
 	}
 
-	if meta.Encrypted {
-		fmt.Fprintf(os.Stderr, "PDF is encrypted. Attempting to decrypt with empty password (owner-password-only PDFs)...\n")
-	}
+	return LoadPDFFromReader(f, info.Size(), opts, extractImages)
+}
 
-	numPages := reader.NumPages()
-	fmt.Fprintf(os.Stderr, "Processing %d pages concurrently...\n", numPages)
+// LoadPDFFromReader is LoadPDFWithOptions for a PDF that's already in
+// memory or otherwise available as an io.ReaderAt - an HTTP response body
+// read into a []byte, an S3 object, an embedded asset - without needing a
+// temp file. size is the PDF's total length, as with io.NewSectionReader.
+// It's a thin wrapper over StreamPDFFromReader with Workers pinned to 1, so
+// pages are extracted (and their stderr progress logged) strictly in page
+// order.
+func LoadPDFFromReader(ra io.ReaderAt, size int64, opts LoadOptions, extractImages bool) (*model.Document, error) {
+	opts.ExtractImages = extractImages
+	opts.Workers = 1
+	return accumulate(ra, size, opts)
+}
 
-	// 4. Process pages concurrently
-	return loadPDFParallel(path, meta, numPages, workers, extractImages)
+// LoadPDFConcurrent loads a PDF and extracts text using concurrent page processing.
+// The workers parameter specifies the number of concurrent workers (0 = auto-detect using NumCPU).
+func LoadPDFConcurrent(path string, workers int, extractImages bool) (*model.Document, error) {
+	return LoadPDFConcurrentWithOptions(path, LoadOptions{}, workers, extractImages)
 }
 
-// loadPDFParallel implements the worker pool pattern for concurrent page extraction
-func loadPDFParallel(path string, meta model.Metadata, numPages int, workers int, extractImages bool) (*model.Document, error) {
-	// 1. Determine worker count
-	if workers <= 0 {
-		workers = runtime.NumCPU()
-	}
-	if workers > numPages {
-		workers = numPages
+// LoadPDFConcurrentWithOptions is LoadPDFConcurrent, generalized to
+// LoadOptions's retry-capable authentication.
+func LoadPDFConcurrentWithOptions(path string, opts LoadOptions, workers int, extractImages bool) (*model.Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
-	// 2. Create channels
-	pageIndices := make(chan int, numPages)
-	results := make(chan pageResult, numPages)
-
-	// 3. Launch workers
-	var wg sync.WaitGroup
-	for w := 0; w < workers; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			// Each worker opens its own file handle
-			f, err := os.Open(path)
-			if err != nil {
-				// Try to send error for first page
-				select {
-				case idx := <-pageIndices:
-					results <- pageResult{pageNum: idx, err: err}
-				default:
-				}
-				return
-			}
-			defer f.Close()
-
-			// Create reader for this worker
-			reader, err := pdf.NewReader(f)
-			if err != nil {
-				select {
-				case idx := <-pageIndices:
-					results <- pageResult{pageNum: idx, err: err}
-				default:
-				}
-				return
-			}
-
-			// Process pages from the channel
-			for pageIdx := range pageIndices {
-				start := time.Now()
-
-				pdfPage, err := reader.GetPage(pageIdx)
-				if err != nil {
-					results <- pageResult{pageNum: pageIdx, err: err}
-					continue
-				}
-
-				extractor, err := pdf.NewExtractor(reader, pdfPage, extractImages)
-				if err != nil {
-					results <- pageResult{pageNum: pageIdx, err: err}
-					continue
-				}
-
-				text, err := extractor.ExtractText()
-				if err != nil {
-					results <- pageResult{pageNum: pageIdx, err: err}
-					continue
-				}
-
-				var width, height float64
-				if mBox, ok := pdfPage["/MediaBox"].(pdf.ArrayObject); ok && len(mBox) == 4 {
-					if w, ok := mBox[2].(pdf.NumberObject); ok {
-						width = float64(w)
-					}
-					if h, ok := mBox[3].(pdf.NumberObject); ok {
-						height = float64(h)
-					}
-				}
-
-				page := model.Page{
-					PageNumber: pageIdx + 1,
-					Content:    text,
-					CharCount:  len(text),
-					Width:      width,
-					Height:     height,
-					Images:     extractor.GetImages(),
-				}
-
-				fmt.Fprintf(os.Stderr, "Page %d processed in %v (%d chars)\n",
-					pageIdx+1, time.Since(start), len(text))
-
-				results <- pageResult{pageNum: pageIdx, page: page, err: nil}
-			}
-		}()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
 	}
 
-	// 4. Send page indices to workers
-	go func() {
-		for i := 0; i < numPages; i++ {
-			pageIndices <- i
-		}
-		close(pageIndices)
-	}()
-
-	// 5. Wait for workers to finish
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// 6. Collect results
-	pages := make([]model.Page, numPages)
-	errorCount := 0
+	return LoadPDFConcurrentFromReader(f, info.Size(), opts, workers, extractImages)
+}
 
-	for result := range results {
-		if result.err != nil {
-			fmt.Fprintf(os.Stderr, "Error processing page %d: %v\n", result.pageNum+1, result.err)
-			errorCount++
-			continue
-		}
-		pages[result.pageNum] = result.page
-	}
+// LoadPDFConcurrentFromReader is LoadPDFConcurrent for a PDF that's already
+// available as an io.ReaderAt, mirroring LoadPDFFromReader. It's a thin
+// wrapper over StreamPDFFromReader with Workers set from workers.
+func LoadPDFConcurrentFromReader(ra io.ReaderAt, size int64, opts LoadOptions, workers int, extractImages bool) (*model.Document, error) {
+	opts.ExtractImages = extractImages
+	opts.Workers = workers
+	return accumulate(ra, size, opts)
+}
 
-	// 7. Filter out empty pages (from errors)
-	validPages := make([]model.Page, 0, numPages-errorCount)
-	for _, page := range pages {
-		if page.PageNumber > 0 { // Skip uninitialized pages
-			validPages = append(validPages, page)
-		}
+// accumulate runs StreamPDFFromReader and collects every emitted page into
+// a *model.Document, the shape every non-streaming Load* entry point
+// returns.
+func accumulate(ra io.ReaderAt, size int64, opts LoadOptions) (*model.Document, error) {
+	var pages []model.Page
+	meta, err := StreamPDFFromReader(ra, size, opts, func(page model.Page) error {
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return &model.Document{
-		Metadata: meta,
-		Pages:    validPages,
-	}, nil
+	return &model.Document{Metadata: meta, Pages: pages}, nil
 }
@@ -0,0 +1,220 @@
+package loader
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/AOShei/pdf-loader/pkg/model"
+	"github.com/AOShei/pdf-loader/pkg/pdf"
+)
+
+// StreamPDF extracts path page by page, delivering each model.Page to emit
+// in page order as soon as it's ready, instead of accumulating a whole
+// *model.Document in memory first - the shape a thousand-page PDF or a
+// downstream NDJSON/ingestion pipeline actually wants. opts.Workers pages
+// are extracted concurrently (0 = runtime.NumCPU()); their out-of-order
+// results are reordered back into page order internally (see streamPages),
+// so callers see the same ordering guarantee a fully sequential loader
+// would give them regardless of how many workers ran. If emit returns an
+// error, no further pages are emitted and that error is returned, wrapped,
+// from StreamPDF; workers still finish extracting the pages already queued
+// to them in the background.
+func StreamPDF(path string, opts LoadOptions, emit func(model.Page) error) (model.Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return model.Metadata{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return model.Metadata{}, err
+	}
+
+	return StreamPDFFromReader(f, info.Size(), opts, emit)
+}
+
+// StreamPDFFromReader is StreamPDF for a PDF already available as an
+// io.ReaderAt, mirroring LoadPDFFromReader.
+func StreamPDFFromReader(ra io.ReaderAt, size int64, opts LoadOptions, emit func(model.Page) error) (model.Metadata, error) {
+	reader, err := authenticate(ra, size, opts)
+	if err != nil {
+		return model.Metadata{}, err
+	}
+
+	meta := buildMetadata(reader, opts)
+	if opts.OnMetadata != nil {
+		opts.OnMetadata(meta)
+	}
+
+	numPages := reader.NumPages()
+	fmt.Fprintf(os.Stderr, "Processing %d pages...\n", numPages)
+
+	if err := streamPages(reader, opts.Workers, opts.ExtractImages, emit); err != nil {
+		return meta, err
+	}
+	return meta, nil
+}
+
+// pageResult holds the outcome of extracting a single page, as produced by
+// a streamPages worker.
+type pageResult struct {
+	pageNum int
+	page    model.Page
+	err     error
+}
+
+// pageHeapItem is one completed page waiting in pendingPages until its
+// predecessors have been emitted.
+type pageHeapItem struct {
+	index int
+	page  model.Page
+}
+
+// pendingPages is a min-heap of pageHeapItem ordered by index, letting
+// streamPages cheaply find "the lowest page number extracted so far" no
+// matter what order the worker pool below finished them in.
+type pendingPages []pageHeapItem
+
+func (h pendingPages) Len() int            { return len(h) }
+func (h pendingPages) Less(i, j int) bool  { return h[i].index < h[j].index }
+func (h pendingPages) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *pendingPages) Push(x interface{}) { *h = append(*h, x.(pageHeapItem)) }
+func (h *pendingPages) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// streamPages extracts every page of reader across workers goroutines
+// (0 = NumCPU), then delivers them to emit strictly in page order. Workers
+// finish in whatever order their pages happen to take, so completed pages
+// are buffered in the pendingPages min-heap until the next expected index
+// is available; a page that errored out still counts as "available" (it's
+// recorded in failed and skipped) so a single slow or broken page can't
+// stall every page after it forever.
+func streamPages(reader *pdf.Reader, workers int, extractImages bool, emit func(model.Page) error) error {
+	numPages := reader.NumPages()
+	if numPages == 0 {
+		return nil
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > numPages {
+		workers = numPages
+	}
+
+	pageIndices := make(chan int, numPages)
+	results := make(chan pageResult, numPages)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pageIdx := range pageIndices {
+				start := time.Now()
+				page, err := extractPage(reader, pageIdx, extractImages)
+				if err == nil {
+					fmt.Fprintf(os.Stderr, "Page %d processed in %v (%d chars)\n", pageIdx+1, time.Since(start), page.CharCount)
+				}
+				results <- pageResult{pageNum: pageIdx, page: page, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for i := 0; i < numPages; i++ {
+			pageIndices <- i
+		}
+		close(pageIndices)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := &pendingPages{}
+	heap.Init(pending)
+	failed := make(map[int]bool)
+	next := 0
+
+	for result := range results {
+		if result.err != nil {
+			fmt.Fprintf(os.Stderr, "Error processing page %d: %v\n", result.pageNum+1, result.err)
+			failed[result.pageNum] = true
+		} else {
+			heap.Push(pending, pageHeapItem{index: result.pageNum, page: result.page})
+		}
+
+		for {
+			if failed[next] {
+				delete(failed, next)
+				next++
+				continue
+			}
+			if pending.Len() > 0 && (*pending)[0].index == next {
+				item := heap.Pop(pending).(pageHeapItem)
+				if err := emit(item.page); err != nil {
+					return fmt.Errorf("emit page %d: %w", item.index+1, err)
+				}
+				next++
+				continue
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// extractPage extracts page pageIdx (0-indexed) from an already-
+// authenticated reader, building the model.Page both the sequential and
+// concurrent loaders need.
+func extractPage(reader *pdf.Reader, pageIdx int, extractImages bool) (model.Page, error) {
+	pdfPage, err := reader.GetPage(pageIdx)
+	if err != nil {
+		return model.Page{}, fmt.Errorf("getting page: %w", err)
+	}
+
+	extractor, err := pdf.NewExtractor(reader, pdfPage, extractImages)
+	if err != nil {
+		return model.Page{}, fmt.Errorf("creating extractor: %w", err)
+	}
+
+	text, err := extractor.ExtractText()
+	if err != nil {
+		return model.Page{}, fmt.Errorf("extracting text: %w", err)
+	}
+
+	// Basic dimensions (MediaBox). [x1 y1 x2 y2] -> width = x2-x1, height =
+	// y2-y1; simplified to assume x1, y1 are 0.
+	var width, height float64
+	mBox := reader.Value(pdfPage).Key("/MediaBox")
+	if mBox.Len() == 4 {
+		if w, ok := mBox.Index(2).Float64(); ok {
+			width = w
+		}
+		if h, ok := mBox.Index(3).Float64(); ok {
+			height = h
+		}
+	}
+
+	return model.Page{
+		PageNumber: pageIdx + 1,
+		Content:    text,
+		CharCount:  len(text),
+		Width:      width,
+		Height:     height,
+		Images:     extractor.GetImages(),
+	}, nil
+}
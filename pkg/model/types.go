@@ -1,5 +1,7 @@
 package model
 
+import "image/color"
+
 // Document represents the final output of the library.
 type Document struct {
 	Metadata Metadata `json:"metadata"`
@@ -14,6 +16,38 @@ type Metadata struct {
 	Producer string `json:"producer,omitempty"`
 	// Encrypted indicates if the file was password protected
 	Encrypted bool `json:"encrypted"`
+	// Linearized indicates the file was saved in "fast web view" form
+	Linearized bool `json:"linearized,omitempty"`
+	// Signatures lists the digital signatures found in the document's
+	// AcroForm, populated only when the caller opts into verification
+	// (loader.LoadOptions.VerifySignatures) since walking /ByteRange and
+	// verifying a PKCS#7 blob is real extra work most callers don't need.
+	Signatures []SignatureInfo `json:"signatures,omitempty"`
+}
+
+// SignatureInfo describes one embedded PKCS#7 (CMS) digital signature, as
+// reported by pdf.Reader.VerifySignatures.
+type SignatureInfo struct {
+	SignerCommonName string `json:"signer_common_name,omitempty"`
+	// SigningTime is RFC 3339, taken from the CMS signingTime signed
+	// attribute. Empty when the signature carries no such attribute.
+	SigningTime     string `json:"signing_time,omitempty"`
+	DigestAlgorithm string `json:"digest_algorithm,omitempty"`
+	SubFilter       string `json:"sub_filter,omitempty"`
+	// CoversWholeFile reports whether /ByteRange spans the entire file
+	// except the /Contents placeholder itself, i.e. nothing was appended
+	// after this signature was applied.
+	CoversWholeFile bool `json:"covers_whole_file"`
+	// Verified is true when the signature cryptographically matches the
+	// signed bytes and the embedded signer certificate. It does not by
+	// itself mean the certificate is trusted - see TrustedChain.
+	Verified bool `json:"verified"`
+	// TrustedChain is true when the signer certificate additionally chains
+	// to a root in the pool passed to VerifySignatures.
+	TrustedChain bool `json:"trusted_chain"`
+	// Error explains why Verified is false, or why this signature could
+	// not be parsed at all.
+	Error string `json:"error,omitempty"`
 }
 
 // Page represents a single page in the PDF.
@@ -28,10 +62,41 @@ type Page struct {
 
 // Image represents an image reference on a page.
 type Image struct {
-	Type       string    `json:"type"`                // "image" or "inline_image"
-	ID         string    `json:"id,omitempty"`        // e.g., "Im1" (empty for inline images)
-	Rect       []float64 `json:"rect,omitempty"`      // [x, y, width, height]
-	Width      float64   `json:"width,omitempty"`     // Image width in pixels
-	Height     float64   `json:"height,omitempty"`    // Image height in pixels
-	ColorSpace string    `json:"color_space,omitempty"` // e.g., "/DeviceRGB"
+	Type             string        `json:"type"`                         // "image" or "inline_image"
+	ID               string        `json:"id,omitempty"`                 // e.g., "Im1" (empty for inline images)
+	Rect             []float64     `json:"rect,omitempty"`               // [x, y, width, height]
+	Width            float64       `json:"width,omitempty"`              // Image width in pixels
+	Height           float64       `json:"height,omitempty"`             // Image height in pixels
+	ColorSpace       string        `json:"color_space,omitempty"`        // e.g., "/DeviceRGB", or the family name ("/Indexed") for array colorspaces
+	BitsPerComponent int           `json:"bits_per_component,omitempty"` // /BitsPerComponent (or /BPC for inline images)
+	Palette          []color.Color `json:"-"`                            // decoded lookup table, populated only for /Indexed ColorSpace
+	Format           string        `json:"format,omitempty"`             // "jpeg", "jpx", "ccitt", "jbig2", or "raw" (unpacked samples)
+	Data             string        `json:"data,omitempty"`               // base64-encoded bitmap bytes, per Format
+
+	IsMask          bool   `json:"is_mask,omitempty"`            // /ImageMask true: a 1-bit stencil painted in the current fill color
+	HasColorKeyMask bool   `json:"has_color_key_mask,omitempty"` // /Mask is a color-range array rather than an explicit mask image
+	Mask            *Image `json:"mask,omitempty"`               // /Mask, when it's an explicit (usually stencil) mask image
+	SMask           *Image `json:"smask,omitempty"`              // /SMask: a separate grayscale image supplying per-pixel alpha
+	SMaskID         string `json:"smask_id,omitempty"`           // the /SMask indirect reference's object number, e.g. "12 0 R"
+
+	AspectRatio float64 `json:"aspect_ratio,omitempty"` // intrinsic Width/Height in source pixels
+	Stretched   bool    `json:"stretched,omitempty"`    // Rect's aspect ratio doesn't match AspectRatio, i.e. CSS object-fit:fill would distort it
+
+	// EffectiveDPIX/EffectiveDPIY are the image's resolution as actually
+	// rendered on the page: source pixels per the 72-points-per-inch PDF
+	// unit, along the Rect's width/height respectively. Equal values mean
+	// the image is scaled uniformly; unequal values mean one axis is
+	// stretched relative to the other.
+	EffectiveDPIX float64 `json:"effective_dpi_x,omitempty"`
+	EffectiveDPIY float64 `json:"effective_dpi_y,omitempty"`
+	// DistortionScore is the relative difference between EffectiveDPIX and
+	// EffectiveDPIY (0 = uniform scaling, larger = more aspect distortion).
+	DistortionScore float64 `json:"distortion_score,omitempty"`
+	// Fit classifies how the image's source pixels map onto Rect, loosely
+	// mirroring CSS object-fit: "stretch" (non-uniform scale, distorts
+	// AspectRatio), "native" (placed at ~72 source pixels per inch, i.e.
+	// undistorted and unscaled), "contain" (scaled down uniformly, so more
+	// source detail than Rect needs), or "cover" (scaled up uniformly, so
+	// less source detail than Rect displays).
+	Fit string `json:"fit,omitempty"`
 }
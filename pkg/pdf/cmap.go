@@ -6,19 +6,37 @@ import (
 	"unicode/utf16"
 )
 
-// CMap represents the mapping from Character Codes (CIDs) to Unicode strings.
+// CodespaceRange is one entry of a CMap's begincodespacerange block: every
+// byte code whose bytes fall within [Low, High] (compared position by
+// position) is len(Low) bytes wide. A CMap with several ranges of
+// different lengths is how PDF expresses mixed 1-/2-byte encodings.
+type CodespaceRange struct {
+	Low, High []byte
+}
+
+// CMap represents a parsed CMap stream, either a ToUnicode map (code ->
+// destination string, via Map) or a CID encoding map (code -> CID, via
+// CIDMap/NotdefCID), plus the codespace ranges needed to know how many
+// bytes make up the next code in a content-stream string. A single stream
+// only ever populates the fields relevant to its kind, but both can be
+// parsed with the same state machine since the keywords don't collide.
 type CMap struct {
-	SpaceWidth float64 // Fallback width
-	Map        map[string]string
+	SpaceWidth      float64 // Fallback width
+	Map             map[string]string
+	CIDMap          map[string]int
+	NotdefCID       map[string]int
+	CodespaceRanges []CodespaceRange
 }
 
 func NewCMap() *CMap {
 	return &CMap{
-		Map: make(map[string]string),
+		Map:       make(map[string]string),
+		CIDMap:    make(map[string]int),
+		NotdefCID: make(map[string]int),
 	}
 }
 
-// ParseCMap parses a ToUnicode stream.
+// ParseCMap parses a ToUnicode or CID-encoding CMap stream.
 func ParseCMap(data []byte) (*CMap, error) {
 	cmap := NewCMap()
 	lexer := NewLexer(bytes.NewReader(data))
@@ -45,12 +63,75 @@ func ParseCMap(data []byte) (*CMap, error) {
 				if err := parseBFRange(lexer, cmap); err != nil {
 					return nil, err
 				}
+			case "begincidchar":
+				if err := parseCIDChar(lexer, cmap); err != nil {
+					return nil, err
+				}
+			case "begincidrange":
+				if err := parseCIDRange(lexer, cmap); err != nil {
+					return nil, err
+				}
+			case "beginnotdefchar":
+				if err := parseNotdefChar(lexer, cmap); err != nil {
+					return nil, err
+				}
+			case "beginnotdefrange":
+				if err := parseNotdefRange(lexer, cmap); err != nil {
+					return nil, err
+				}
+			case "begincodespacerange":
+				if err := parseCodespaceRange(lexer, cmap); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
 	return cmap, nil
 }
 
+// CodeLength returns how many leading bytes of data make up the next
+// character code, per the declared codespace ranges (checked byte by byte
+// against each range's Low/High of the same length). Per spec, a code that
+// doesn't match any declared range falls back to 1 byte; a CMap with no
+// codespace ranges at all (e.g. a bare ToUnicode stream parsed only for
+// its bfchar/bfrange entries) does the same.
+func (cm *CMap) CodeLength(data []byte) int {
+	for _, r := range cm.CodespaceRanges {
+		n := len(r.Low)
+		if n == 0 || n > len(data) {
+			continue
+		}
+		match := true
+		for i := 0; i < n; i++ {
+			if data[i] < r.Low[i] || data[i] > r.High[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return n
+		}
+	}
+	if len(data) == 0 {
+		return 0
+	}
+	return 1
+}
+
+// CIDForCode resolves a raw character code (its bytes, not hex-encoded) to
+// a CID, falling back to any notdefchar/notdefrange covering the code and
+// finally to CID 0, the standard "missing glyph" CID.
+func (cm *CMap) CIDForCode(code []byte) int {
+	key := string(code)
+	if cid, ok := cm.CIDMap[key]; ok {
+		return cid
+	}
+	if cid, ok := cm.NotdefCID[key]; ok {
+		return cid
+	}
+	return 0
+}
+
 // parseBFChar handles: <srcCode> <dstString>
 func parseBFChar(l *Lexer, cmap *CMap) error {
 	// Loop until endbfchar
@@ -138,25 +219,203 @@ func parseBFRange(l *Lexer, cmap *CMap) error {
 				}
 			}
 		} else if dstStartHex, ok := nextObj.(HexStringObject); ok {
-			// Case 1: Sequential <dstStart>
-			// We map startCode..endCode to dstStart..dstStart+(diff)
-			// Logic: The destination code increments too.
-			// NOTE: Handle UTF16 incrementing carefully.
-
-			dstCode := hexToInt(dstStartHex)
-
+			// Case 1: Sequential <dstStart>. Per spec only the last byte
+			// increments per step; anything before that is a fixed
+			// prefix of extra destination code points (a single source
+			// code mapping to a multi-codepoint string).
 			for i := 0; i <= (endCode - startCode); i++ {
 				srcKey := intToHex(startCode+i, len(startHex))
-				// This is a simplification. Real unicode incrementing is complex.
-				// However, PDF spec says the last byte increments.
-				dstVal := intToHex(dstCode+i, len(dstStartHex))
-				cmap.Map[srcKey] = decodeUTF16BE(HexStringObject(dstVal))
+				dstVal := incrementTail([]byte(dstStartHex), i)
+				cmap.Map[srcKey] = decodeUTF16BE(dstVal)
 			}
 		}
 		// If nextObj is neither array nor hex string, skip this entry
 	}
 }
 
+// parseCIDChar handles: <code> cid
+func parseCIDChar(l *Lexer, cmap *CMap) error {
+	for {
+		codeObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+		if keyword, ok := codeObj.(KeywordObject); ok {
+			if string(keyword) == "endcidchar" {
+				return nil
+			}
+			continue
+		}
+
+		cidObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+
+		codeHex, ok1 := codeObj.(HexStringObject)
+		cid, ok2 := cidObj.(NumberObject)
+		if ok1 && ok2 {
+			cmap.CIDMap[string(codeHex)] = int(cid)
+		}
+	}
+}
+
+// parseCIDRange handles: <start> <end> cid, with cid incrementing across
+// the range just like a bfrange's sequential destination does.
+func parseCIDRange(l *Lexer, cmap *CMap) error {
+	for {
+		startObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+		if keyword, ok := startObj.(KeywordObject); ok {
+			if string(keyword) == "endcidrange" {
+				return nil
+			}
+			continue
+		}
+
+		endObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+		cidObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+
+		startHex, startOk := startObj.(HexStringObject)
+		endHex, endOk := endObj.(HexStringObject)
+		cidStart, cidOk := cidObj.(NumberObject)
+		if !startOk || !endOk || !cidOk {
+			continue
+		}
+
+		startCode := hexToInt(startHex)
+		endCode := hexToInt(endHex)
+		for i := 0; i <= (endCode - startCode); i++ {
+			key := intToHex(startCode+i, len(startHex))
+			cmap.CIDMap[key] = int(cidStart) + i
+		}
+	}
+}
+
+// parseNotdefChar handles: <code> cid, same shape as begincidchar but
+// filed under the notdef fallback table instead.
+func parseNotdefChar(l *Lexer, cmap *CMap) error {
+	for {
+		codeObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+		if keyword, ok := codeObj.(KeywordObject); ok {
+			if string(keyword) == "endnotdefchar" {
+				return nil
+			}
+			continue
+		}
+
+		cidObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+
+		codeHex, ok1 := codeObj.(HexStringObject)
+		cid, ok2 := cidObj.(NumberObject)
+		if ok1 && ok2 {
+			cmap.NotdefCID[string(codeHex)] = int(cid)
+		}
+	}
+}
+
+// parseNotdefRange handles: <start> <end> cid. Unlike begincidrange, every
+// code in the range maps to the *same* cid — it's one fallback glyph for
+// a whole range of otherwise-unmapped codes, not a sequential mapping.
+func parseNotdefRange(l *Lexer, cmap *CMap) error {
+	for {
+		startObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+		if keyword, ok := startObj.(KeywordObject); ok {
+			if string(keyword) == "endnotdefrange" {
+				return nil
+			}
+			continue
+		}
+
+		endObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+		cidObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+
+		startHex, startOk := startObj.(HexStringObject)
+		endHex, endOk := endObj.(HexStringObject)
+		cid, cidOk := cidObj.(NumberObject)
+		if !startOk || !endOk || !cidOk {
+			continue
+		}
+
+		startCode := hexToInt(startHex)
+		endCode := hexToInt(endHex)
+		for i := 0; i <= (endCode - startCode); i++ {
+			key := intToHex(startCode+i, len(startHex))
+			cmap.NotdefCID[key] = int(cid)
+		}
+	}
+}
+
+// parseCodespaceRange handles: <lowN> <highN> pairs until endcodespacerange.
+func parseCodespaceRange(l *Lexer, cmap *CMap) error {
+	for {
+		lowObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+		if keyword, ok := lowObj.(KeywordObject); ok {
+			if string(keyword) == "endcodespacerange" {
+				return nil
+			}
+			continue
+		}
+
+		highObj, err := l.ReadObject()
+		if err != nil {
+			return err
+		}
+
+		lowHex, lowOk := lowObj.(HexStringObject)
+		highHex, highOk := highObj.(HexStringObject)
+		if lowOk && highOk {
+			cmap.CodespaceRanges = append(cmap.CodespaceRanges, CodespaceRange{
+				Low:  []byte(lowHex),
+				High: []byte(highHex),
+			})
+		}
+	}
+}
+
+// incrementTail returns a copy of dst with its last byte increased by
+// delta, per the PDF spec's rule for a bfrange with a sequential
+// <dstStart>: only the last byte increments for each code in the range, and
+// if that would overflow the byte boundary the overflow is simply dropped
+// (wrapping back to 0) rather than carrying into the preceding byte. Any
+// bytes before the last are left untouched.
+func incrementTail(dst []byte, delta int) []byte {
+	out := make([]byte, len(dst))
+	copy(out, dst)
+	if len(out) == 0 || delta == 0 {
+		return out
+	}
+	last := len(out) - 1
+	out[last] = byte(int(out[last]) + delta)
+	return out
+}
+
 // Helpers
 
 func hexToInt(h HexStringObject) int {
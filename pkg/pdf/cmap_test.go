@@ -0,0 +1,76 @@
+package pdf
+
+import "testing"
+
+// TestParseCMapCIDRangeAndCodespace builds a CID-encoding CMap stream with a
+// begincidrange, a notdefrange fallback, and a begincodespacerange, and
+// checks that CIDForCode/CodeLength resolve through all three the way a
+// CID/Type0 font's embedded /Encoding CMap would be used.
+func TestParseCMapCIDRangeAndCodespace(t *testing.T) {
+	data := []byte(`
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+1 begincidrange
+<0020> <0024> 10
+endcidrange
+1 begincidchar
+<0030> 500
+endcidchar
+1 beginnotdefrange
+<0000> <001F> 1
+endnotdefrange
+`)
+
+	cmap, err := ParseCMap(data)
+	if err != nil {
+		t.Fatalf("ParseCMap: %v", err)
+	}
+
+	if got := cmap.CodeLength([]byte{0x00, 0x21}); got != 2 {
+		t.Fatalf("CodeLength = %d, want 2 (from the declared 2-byte codespace range)", got)
+	}
+
+	// 0x0020 + 4 = 0x0024, within the range, so CID = 10 + 4 = 14.
+	if got := cmap.CIDForCode([]byte{0x00, 0x24}); got != 14 {
+		t.Fatalf("CIDForCode(0x0024) = %d, want 14 (range start 10 + offset 4)", got)
+	}
+
+	if got := cmap.CIDForCode([]byte{0x00, 0x30}); got != 500 {
+		t.Fatalf("CIDForCode(0x0030) = %d, want 500 (single cidchar entry)", got)
+	}
+
+	// Not in CIDMap at all, but covered by the notdefrange fallback.
+	if got := cmap.CIDForCode([]byte{0x00, 0x10}); got != 1 {
+		t.Fatalf("CIDForCode(0x0010) = %d, want 1 (notdefrange fallback)", got)
+	}
+
+	// Outside every range and every fallback: CID 0, the standard
+	// missing-glyph CID.
+	if got := cmap.CIDForCode([]byte{0x7F, 0xFF}); got != 0 {
+		t.Fatalf("CIDForCode(0x7FFF) = %d, want 0 (no match, no fallback)", got)
+	}
+}
+
+// TestIncrementTailWrapsWithoutCarry is a regression test for incrementTail
+// treating a bfrange's sequential <dstStart> as a 16-bit big-endian value:
+// the PDF spec only increments the last byte, and an overflow there wraps
+// back to 0 instead of carrying into the preceding byte.
+func TestIncrementTailWrapsWithoutCarry(t *testing.T) {
+	got := incrementTail([]byte{0x00, 0xFF}, 1)
+	want := []byte{0x00, 0x00}
+	if string(got) != string(want) {
+		t.Fatalf("incrementTail({0x00, 0xFF}, 1) = %v, want %v (wrap, no carry)", got, want)
+	}
+}
+
+// TestIncrementTailLeavesPrefixUntouched checks the ordinary, non-overflowing
+// case still only advances the last byte, leaving any fixed prefix bytes
+// (the multi-codepoint-destination case) alone.
+func TestIncrementTailLeavesPrefixUntouched(t *testing.T) {
+	got := incrementTail([]byte{0x41, 0x00, 0x20}, 4)
+	want := []byte{0x41, 0x00, 0x24}
+	if string(got) != string(want) {
+		t.Fatalf("incrementTail({0x41, 0x00, 0x20}, 4) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Operation is one operator and its operands from a page content stream,
+// e.g. "1 0 0 1 100 700 cm" becomes Operation{Operator: "cm", Operands:
+// [NumberObject(1), NumberObject(0), ...]}. Inline images (the "BI ... ID
+// ... EI" sequence) are surfaced as a single synthetic Operation whose
+// Operator is "INLINE_IMAGE", whose one Operand is the image dictionary
+// built from the BI/ID key-value pairs, and whose InlineData holds the raw
+// (still-encoded) sample bytes between ID and EI, for callers that want the
+// actual bitmap rather than just the dictionary's metadata.
+type Operation struct {
+	Operator   string
+	Operands   []Object
+	InlineData []byte
+}
+
+// ContentStreamParser tokenizes a page content stream's already-decoded
+// bytes into a sequence of Operations. It reuses Lexer for the object
+// grammar (numbers, names, strings, arrays, dictionaries) since a content
+// stream's operands are ordinary PDF objects; only the operators
+// themselves, and the special BI/ID/EI inline-image sequence, aren't
+// objects the Lexer already knows how to read.
+type ContentStreamParser struct {
+	lexer    *Lexer
+	operands []Object
+}
+
+// NewContentStreamParser returns a parser over data, the decoded bytes of
+// one page /Contents stream.
+func NewContentStreamParser(data []byte) *ContentStreamParser {
+	return &ContentStreamParser{lexer: NewLexer(bytes.NewReader(data))}
+}
+
+// Next returns the next Operation, or io.EOF once the stream is exhausted.
+func (p *ContentStreamParser) Next() (*Operation, error) {
+	for {
+		obj, err := p.lexer.ReadObject()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		keyword, ok := obj.(KeywordObject)
+		if !ok {
+			p.operands = append(p.operands, obj)
+			continue
+		}
+
+		if string(keyword) == "BI" {
+			op, err := p.readInlineImage()
+			p.operands = nil
+			return op, err
+		}
+
+		op := &Operation{Operator: string(keyword), Operands: p.operands}
+		p.operands = nil
+		return op, nil
+	}
+}
+
+// readInlineImage consumes a "BI <key value>* ID <raw data> EI" sequence
+// (the BI keyword itself already consumed by Next) and returns it as a
+// single INLINE_IMAGE Operation wrapping the key/value pairs as a
+// DictionaryObject.
+func (p *ContentStreamParser) readInlineImage() (*Operation, error) {
+	dict := make(DictionaryObject)
+	for {
+		p.lexer.skipWhitespace()
+		peek, _ := p.lexer.reader.Peek(2)
+		if len(peek) >= 2 && string(peek) == "ID" {
+			p.lexer.reader.Discard(2)
+			break
+		}
+
+		keyObj, err := p.lexer.ReadObject()
+		if err != nil {
+			return nil, fmt.Errorf("inline image: reading key: %w", err)
+		}
+		key, ok := keyObj.(NameObject)
+		if !ok {
+			return nil, fmt.Errorf("inline image: dictionary key must be a name, got %T", keyObj)
+		}
+
+		valObj, err := p.lexer.ReadObject()
+		if err != nil {
+			return nil, fmt.Errorf("inline image: reading value for %s: %w", key, err)
+		}
+		dict[string(key)] = valObj
+	}
+
+	// Exactly one whitespace byte separates "ID" from the binary data.
+	if b, err := p.lexer.reader.ReadByte(); err == nil && !isWhitespace(b) {
+		p.lexer.reader.UnreadByte()
+	}
+
+	data, err := p.readToEI()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Operation{Operator: "INLINE_IMAGE", Operands: []Object{dict}, InlineData: data}, nil
+}
+
+// readToEI reads the inline image's raw sample bytes up to (but not
+// including) the terminating "EI", which must be preceded by whitespace and
+// followed by whitespace, a delimiter, or EOF so a same-byte sequence inside the image
+// data itself isn't mistaken for the terminator. The trailing whitespace
+// byte immediately before "EI" is part of the terminator, not sample data,
+// so it's trimmed from the returned bytes.
+func (p *ContentStreamParser) readToEI() ([]byte, error) {
+	var data bytes.Buffer
+	var prev byte
+	havePrev := false
+	for {
+		b, err := p.lexer.reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("inline image: missing EI terminator: %w", err)
+		}
+		if b == 'I' && havePrev && prev == 'E' {
+			out := data.Bytes()
+			// The "E" is out[n-1]; the byte before it must be whitespace,
+			// unless the "E" is the first byte (empty sample data).
+			precededOK := len(out) < 2 || isWhitespace(out[len(out)-2])
+			next, _ := p.lexer.reader.Peek(1)
+			if precededOK && (len(next) == 0 || isWhitespace(next[0]) || isDelimiter(next[0])) {
+				// Drop the already-written "E" and the whitespace byte
+				// separating the sample data from the "EI" terminator.
+				if n := len(out); n > 0 && out[n-1] == 'E' {
+					out = out[:n-1]
+				}
+				if n := len(out); n > 0 && isWhitespace(out[n-1]) {
+					out = out[:n-1]
+				}
+				return out, nil
+			}
+		}
+		data.WriteByte(b)
+		prev = b
+		havePrev = true
+	}
+}
@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/AOShei/pdf-loader/pkg/model"
+)
+
+// TestReadInlineImageCapturesRawData parses a minimal "BI ... ID <raw> EI"
+// sequence and checks both the dictionary operand and the raw sample bytes
+// come back intact - regression test for skipToEI previously discarding the
+// sample bytes entirely instead of returning them as Operation.InlineData.
+func TestReadInlineImageCapturesRawData(t *testing.T) {
+	var stream bytes.Buffer
+	stream.WriteString("BI /W 2 /H 2 /CS /G ID ")
+	sample := []byte{0x01, 0x02, 0x03, 0x04}
+	stream.Write(sample)
+	stream.WriteString(" EI")
+
+	p := NewContentStreamParser(stream.Bytes())
+	op, err := p.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if op.Operator != "INLINE_IMAGE" {
+		t.Fatalf("Operator = %q, want INLINE_IMAGE", op.Operator)
+	}
+	if !bytes.Equal(op.InlineData, sample) {
+		t.Fatalf("InlineData = %v, want %v", op.InlineData, sample)
+	}
+
+	dict, ok := op.Operands[0].(DictionaryObject)
+	if !ok {
+		t.Fatalf("operand 0 is %T, want DictionaryObject", op.Operands[0])
+	}
+	if dict["/W"] != NumberObject(2) || dict["/H"] != NumberObject(2) {
+		t.Fatalf("unexpected dict: %v", dict)
+	}
+
+	if _, err := p.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF after the inline image, got %v", err)
+	}
+}
+
+// TestReadToEIIgnoresEIInsideSampleData checks that an "EI" byte pair
+// embedded in unfiltered sample data (not surrounded by whitespace/
+// delimiters) isn't mistaken for the terminator.
+func TestReadToEIIgnoresEIInsideSampleData(t *testing.T) {
+	var stream bytes.Buffer
+	stream.WriteString("BI /W 1 /H 1 ID ")
+	sample := []byte{'x', 'E', 'I', 'y'}
+	stream.Write(sample)
+	stream.WriteString(" EI")
+
+	p := NewContentStreamParser(stream.Bytes())
+	op, err := p.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if !bytes.Equal(op.InlineData, sample) {
+		t.Fatalf("InlineData = %v, want %v", op.InlineData, sample)
+	}
+}
+
+// TestPopulateInlineImageBitmapDecodesData exercises populateInlineImageBitmap
+// directly against an unfiltered inline image dictionary, confirming it fills
+// in Data/Format the same way recordImage does for XObject images - the
+// behavior the review asked for in place of inline images never getting a
+// decoded bitmap at all.
+func TestPopulateInlineImageBitmapDecodesData(t *testing.T) {
+	e := &Extractor{}
+	dict := DictionaryObject{"/W": NumberObject(1), "/H": NumberObject(1)}
+	sample := []byte{0xAA, 0xBB, 0xCC}
+
+	var img model.Image
+	e.populateInlineImageBitmap(&img, dict, sample)
+
+	want := base64.StdEncoding.EncodeToString(sample)
+	if img.Data != want {
+		t.Fatalf("Data = %q, want %q", img.Data, want)
+	}
+	if img.Format != "raw" {
+		t.Fatalf("Format = %q, want raw", img.Format)
+	}
+}
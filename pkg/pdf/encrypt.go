@@ -5,6 +5,8 @@ import (
 	"crypto/cipher"
 	"crypto/md5"
 	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
 	"errors"
 	"fmt"
 )
@@ -12,15 +14,47 @@ import (
 // EncryptDict represents the PDF encryption dictionary
 type EncryptDict struct {
 	Filter          string // Should be "/Standard"
-	V               int    // Version: 1, 2, 4
-	R               int    // Revision: 2, 3, 4
+	V               int    // Version: 1, 2, 4, 5
+	R               int    // Revision: 2, 3, 4, 5, 6
 	O               []byte // Owner password hash (48 bytes)
 	U               []byte // User password hash (48 bytes)
+	OE              []byte // R5/R6: AES-256-encrypted key material for the owner password (32 bytes)
+	UE              []byte // R5/R6: AES-256-encrypted key material for the user password (32 bytes)
+	Perms           []byte // R5/R6: AES-256-ECB-encrypted /P consistency check (16 bytes, optional)
 	P               int32  // Permission flags
-	Length          int    // Key length in bits (40, 128)
+	Length          int    // Key length in bits (40, 128, 256)
 	EncryptMetadata bool   // Usually true
+
+	// CF, StmF, StrF and EFF are only meaningful for V>=4, where the single
+	// algorithm-per-V model gives way to named crypt filters: CF maps a
+	// filter name (e.g. "/StdCF") to its algorithm, and StmF/StrF/EFF say
+	// which filter applies to streams, strings, and embedded files
+	// respectively. A name of "/Identity" means "don't decrypt at all" for
+	// that scope; it is the default for an absent /StmF or /StrF, while an
+	// absent /EFF defaults to StmF.
+	CF   map[string]CryptFilter
+	StmF string
+	StrF string
+	EFF  string
+}
+
+// CryptFilter is one entry of the encryption dictionary's /CF dictionary.
+type CryptFilter struct {
+	CFM       string // "/V2" (RC4), "/AESV2" (AES-128), "/AESV3" (AES-256), "/None", or "/Identity" when /CFM is absent
+	AuthEvent string // "/DocOpen" or "/EFOpen"
+	Length    int    // Optional per-filter key length in bytes; 0 means "use the document default"
 }
 
+// CryptFilterScope selects which of a document's named crypt filters
+// (/StmF, /StrF, /EFF) governs a given Decrypt call.
+type CryptFilterScope int
+
+const (
+	CryptFilterScopeStream CryptFilterScope = iota
+	CryptFilterScopeString
+	CryptFilterScopeEmbeddedFile
+)
+
 // EncryptionHandler handles PDF encryption/decryption
 type EncryptionHandler struct {
 	Dict       *EncryptDict
@@ -28,8 +62,22 @@ type EncryptionHandler struct {
 	EncryptKey []byte // Computed encryption key
 	V          int    // Algorithm version
 	R          int    // Standard security handler revision
+
+	// PermsValid reports whether /Perms (R5/R6 only) decrypts to a /P value
+	// consistent with the dictionary's actual /P entry. The spec describes
+	// this purely as a sanity check for readers, not an authentication
+	// requirement, so a false value (or a missing /Perms) never causes
+	// NewEncryptionHandler to fail.
+	PermsValid bool
 }
 
+// ErrWrongPassword is returned by NewEncryptionHandler (and so by
+// NewReaderWithPassword) when the supplied password validates as neither
+// the user nor the owner password. Callers that want to prompt for another
+// password should check for it with errors.Is rather than matching on the
+// error text.
+var ErrWrongPassword = errors.New("pdf: incorrect password")
+
 // PDF standard padding string (32 bytes) - from PDF spec
 var paddingString = []byte{
 	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41,
@@ -103,14 +151,90 @@ func ParseEncryptDict(obj Object, reader *Reader) (*EncryptDict, error) {
 		return nil, errors.New("missing or invalid /P in encryption dictionary")
 	}
 
+	// R5/R6 (V5, AES-256) carry the encrypted file key alongside O/U.
+	if encDict.R >= 5 {
+		oe := reader.Resolve(dict["/OE"])
+		switch v := oe.(type) {
+		case StringObject:
+			encDict.OE = []byte(v)
+		case HexStringObject:
+			encDict.OE = []byte(v)
+		default:
+			return nil, errors.New("missing or invalid /OE for R5/R6 encryption")
+		}
+
+		ue := reader.Resolve(dict["/UE"])
+		switch v := ue.(type) {
+		case StringObject:
+			encDict.UE = []byte(v)
+		case HexStringObject:
+			encDict.UE = []byte(v)
+		default:
+			return nil, errors.New("missing or invalid /UE for R5/R6 encryption")
+		}
+
+		// /Perms is optional in practice even though the spec mandates it;
+		// it's only used as an extra integrity check (see verifyPerms),
+		// never to derive the key, so a missing or malformed entry isn't
+		// fatal.
+		switch v := reader.Resolve(dict["/Perms"]).(type) {
+		case StringObject:
+			encDict.Perms = []byte(v)
+		case HexStringObject:
+			encDict.Perms = []byte(v)
+		}
+	}
+
+	// V4/V5 replace the single algorithm-for-everything model with named
+	// crypt filters; parse /CF, /StmF, /StrF and /EFF so streams and strings
+	// can be decrypted per their own scope instead of assuming /StmF covers
+	// everything (the assumption the rest of this package made previously).
+	if encDict.V >= 4 {
+		encDict.StmF = "/Identity"
+		encDict.StrF = "/Identity"
+
+		if cfDict, ok := reader.Resolve(dict["/CF"]).(DictionaryObject); ok {
+			encDict.CF = make(map[string]CryptFilter, len(cfDict))
+			for name, val := range cfDict {
+				cf := CryptFilter{CFM: "/Identity", AuthEvent: "/DocOpen"}
+				if cfEntry, ok := reader.Resolve(val).(DictionaryObject); ok {
+					if cfm, ok := cfEntry["/CFM"].(NameObject); ok {
+						cf.CFM = string(cfm)
+					}
+					if ae, ok := cfEntry["/AuthEvent"].(NameObject); ok {
+						cf.AuthEvent = string(ae)
+					}
+					if l, ok := cfEntry["/Length"].(NumberObject); ok {
+						cf.Length = int(l)
+					}
+				}
+				encDict.CF[name] = cf
+			}
+		}
+
+		if stmF, ok := dict["/StmF"].(NameObject); ok {
+			encDict.StmF = string(stmF)
+		}
+		if strF, ok := dict["/StrF"].(NameObject); ok {
+			encDict.StrF = string(strF)
+		}
+		encDict.EFF = encDict.StmF
+		if eff, ok := dict["/EFF"].(NameObject); ok {
+			encDict.EFF = string(eff)
+		}
+	}
+
 	// Extract Length (key length in bits)
 	if length, ok := dict["/Length"].(NumberObject); ok {
 		encDict.Length = int(length)
 	} else {
 		// Default lengths based on revision
-		if encDict.R == 2 {
+		switch {
+		case encDict.R == 2:
 			encDict.Length = 40
-		} else {
+		case encDict.R >= 5:
+			encDict.Length = 256
+		default:
 			encDict.Length = 128
 		}
 	}
@@ -123,8 +247,12 @@ func ParseEncryptDict(obj Object, reader *Reader) (*EncryptDict, error) {
 	return encDict, nil
 }
 
-// NewEncryptionHandler creates a new encryption handler with empty password
-func NewEncryptionHandler(encDict *EncryptDict, fileID []byte) (*EncryptionHandler, error) {
+// NewEncryptionHandler creates a new encryption handler authenticated with
+// password, trying it first as the user password (Algorithm 6) and then,
+// for R2-R4, as the owner password (Algorithm 7 recovers the user password
+// from /O, then Algorithm 2 derives the key from that). R5/R6 try both in
+// computeEncryptionKeyR6, since /U and /O there are validated the same way.
+func NewEncryptionHandler(encDict *EncryptDict, fileID []byte, password string) (*EncryptionHandler, error) {
 	if encDict == nil {
 		return nil, errors.New("encryption dictionary is nil")
 	}
@@ -136,10 +264,95 @@ func NewEncryptionHandler(encDict *EncryptDict, fileID []byte) (*EncryptionHandl
 		R:      encDict.R,
 	}
 
-	// Compute encryption key with empty password (for owner-password-only PDFs)
-	handler.EncryptKey = handler.computeEncryptionKey([]byte{})
+	pw := []byte(password)
+
+	if encDict.R >= 5 {
+		key, err := handler.computeEncryptionKeyR6(pw)
+		if err != nil {
+			return nil, err
+		}
+		handler.EncryptKey = key
+		handler.PermsValid = handler.verifyPerms()
+		return handler, nil
+	}
+
+	handler.EncryptKey = handler.computeEncryptionKey(pw)
+	if handler.validatesAgainstU() {
+		return handler, nil
+	}
+
+	// pw didn't validate as the user password; try it as the owner password.
+	if recovered, err := handler.recoverUserPassword(pw); err == nil {
+		handler.EncryptKey = handler.computeEncryptionKey(recovered)
+		if handler.validatesAgainstU() {
+			return handler, nil
+		}
+	}
+
+	if len(pw) == 0 {
+		return nil, fmt.Errorf("%w: empty password did not validate against /U: this PDF requires a user or owner password", ErrWrongPassword)
+	}
+	return nil, fmt.Errorf("%w: did not validate as either the user or owner password", ErrWrongPassword)
+}
+
+// validatesAgainstU reports whether h.EncryptKey's derived /U value (as
+// computeUValue would produce it) matches the document's actual /U entry.
+func (h *EncryptionHandler) validatesAgainstU() bool {
+	computed := h.computeUValue()
+	return len(h.Dict.U) >= len(computed) && bytesEqual(computed, h.Dict.U[:len(computed)])
+}
+
+// computeOwnerRC4Key derives the RC4 key used to encrypt/decrypt /O (the
+// first few steps of Algorithm 3, shared with Algorithm 7): pad the owner
+// password, MD5 it, and for R>=3 iterate the hash 50 more times.
+func (h *EncryptionHandler) computeOwnerRC4Key(ownerPassword []byte) []byte {
+	padded := padPassword(ownerPassword)
+	sum := md5.Sum(padded)
+	digest := sum[:]
+
+	keyLen := h.Dict.Length / 8
+	if h.R >= 3 {
+		for i := 0; i < 50; i++ {
+			s := md5.Sum(digest[:keyLen])
+			digest = s[:]
+		}
+	}
+	return digest[:keyLen]
+}
+
+// recoverUserPassword implements Algorithm 7: decrypt /O with the RC4 key
+// derived from the (candidate) owner password to recover the padded user
+// password, which Algorithm 2 can then turn into a file encryption key.
+func (h *EncryptionHandler) recoverUserPassword(ownerPassword []byte) ([]byte, error) {
+	key := h.computeOwnerRC4Key(ownerPassword)
+
+	out := make([]byte, len(h.Dict.O))
+	copy(out, h.Dict.O)
+
+	if h.R == 2 {
+		c, err := rc4.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		c.XORKeyStream(out, out)
+		return out, nil
+	}
 
-	return handler, nil
+	// R>=3 applies 20 RC4 passes when computing /O, each with the key
+	// XORed by the (0-based) round number; undoing it means running the
+	// same passes in reverse order.
+	for i := 19; i >= 0; i-- {
+		roundKey := make([]byte, len(key))
+		for j := range roundKey {
+			roundKey[j] = key[j] ^ byte(i)
+		}
+		c, err := rc4.NewCipher(roundKey)
+		if err != nil {
+			return nil, err
+		}
+		c.XORKeyStream(out, out)
+	}
+	return out, nil
 }
 
 // padPassword pads or truncates password to 32 bytes using PDF standard padding
@@ -207,6 +420,222 @@ func (h *EncryptionHandler) computeEncryptionKey(password []byte) []byte {
 	return digest[:keyLen]
 }
 
+// computeEncryptionKeyR6 derives the AES-256 file encryption key for R5/R6
+// (V5) documents by validating the password against /U (falling back to
+// /O, i.e. the owner password) and then AES-256-CBC-decrypting the matching
+// UE/OE entry with the intermediate key hash. U/O each pack 48 bytes: a
+// 32-byte hash, an 8-byte validation salt, and an 8-byte key salt.
+func (h *EncryptionHandler) computeEncryptionKeyR6(password []byte) ([]byte, error) {
+	// SASLprep normalization (ISO 32000-2) is a no-op for the empty/ASCII
+	// passwords this loader authenticates with in practice, so we hash the
+	// password bytes as given.
+	if len(h.Dict.U) < 48 {
+		return nil, errors.New("/U entry too short for R5/R6")
+	}
+
+	uHash, uValidationSalt, uKeySalt := h.Dict.U[:32], h.Dict.U[32:40], h.Dict.U[40:48]
+
+	if bytesEqual(h.hashR6(password, uValidationSalt, nil), uHash) {
+		intermediate := h.hashR6(password, uKeySalt, nil)
+		return aesCBCNoPadding(intermediate, h.Dict.UE)
+	}
+
+	if len(h.Dict.O) >= 48 {
+		oHash, oValidationSalt, oKeySalt := h.Dict.O[:32], h.Dict.O[32:40], h.Dict.O[40:48]
+		// The owner password hash also folds in the (already-encrypted) /U
+		// string, per Algorithm 9.
+		if bytesEqual(h.hashR6(password, oValidationSalt, h.Dict.U), oHash) {
+			intermediate := h.hashR6(password, oKeySalt, h.Dict.U)
+			return aesCBCNoPadding(intermediate, h.Dict.OE)
+		}
+	}
+
+	// Neither password matched. For the empty password, still fall back to
+	// the user-key derivation so owner-password-only files (the common case
+	// for an empty password, where the validation hashes above are expected
+	// to match already) decrypt rather than hard-failing on an edge case.
+	// A non-empty password that matched neither hash is a genuine wrong
+	// password, not an edge case to paper over - report it instead of
+	// silently deriving a key that will only decrypt to garbage.
+	if len(password) == 0 {
+		intermediate := h.hashR6(password, uKeySalt, nil)
+		return aesCBCNoPadding(intermediate, h.Dict.UE)
+	}
+	return nil, fmt.Errorf("%w: did not validate as either the user or owner password", ErrWrongPassword)
+}
+
+// hashR6 computes the password hash used for both validation and key
+// unwrapping. For R5 it's a single SHA-256 pass; for R6 it's the hardened
+// hash cascade from ISO 32000-2 Algorithm 2.B: each round AES-128-CBC-
+// encrypts 64 copies of password||K||udata (key K[:16], IV K[16:32]) into
+// E, then sets K to the SHA-256/384/512 hash of E (selected by the sum of
+// E's first 16 bytes mod 3). It runs at least 64 rounds and stops once the
+// last byte of E is <= round-32.
+func (h *EncryptionHandler) hashR6(password, salt, udata []byte) []byte {
+	input := append(append(append([]byte{}, password...), salt...), udata...)
+	sum := sha256.Sum256(input)
+	k := sum[:]
+
+	if h.R < 6 {
+		return k
+	}
+
+	round := 0
+	for {
+		k1 := make([]byte, 0, 64*(len(password)+len(k)+len(udata)))
+		for i := 0; i < 64; i++ {
+			k1 = append(k1, password...)
+			k1 = append(k1, k...)
+			k1 = append(k1, udata...)
+		}
+
+		block, err := aes.NewCipher(k[:16])
+		if err != nil {
+			return k[:32]
+		}
+		mode := cipher.NewCBCEncrypter(block, k[16:32])
+		e := make([]byte, len(k1))
+		mode.CryptBlocks(e, k1)
+
+		mod := sumModThree(e[:16])
+		switch mod {
+		case 0:
+			s := sha256.Sum256(e)
+			k = s[:]
+		case 1:
+			s := sha512.Sum384(e)
+			k = s[:]
+		default:
+			s := sha512.Sum512(e)
+			k = s[:]
+		}
+
+		round++
+		if round >= 64 && int(e[len(e)-1]) <= round-32 {
+			break
+		}
+	}
+
+	return k[:32]
+}
+
+// verifyPerms decrypts /Perms (when present) with a single, unchained
+// AES-256 block (ECB, no IV, no padding — /Perms is exactly one block) and
+// checks it against /P per ISO 32000-2 7.6.4.3.3: bytes 0-3 hold P itself
+// (not just the low 4 bytes - P is already a 32-bit quantity) as a
+// little-endian int32, bytes 4-7 are fixed 0xFF, byte 8 is 'T'/'F' mirroring
+// /EncryptMetadata, and bytes 9-11 are the literal ASCII "adb". A missing or
+// malformed /Perms simply reports false; see PermsValid's doc comment.
+func (h *EncryptionHandler) verifyPerms() bool {
+	if len(h.Dict.Perms) != 16 || len(h.EncryptKey) != 32 {
+		return false
+	}
+
+	block, err := aes.NewCipher(h.EncryptKey)
+	if err != nil {
+		return false
+	}
+	decrypted := make([]byte, 16)
+	block.Decrypt(decrypted, h.Dict.Perms)
+
+	p := int32(decrypted[0]) | int32(decrypted[1])<<8 | int32(decrypted[2])<<16 | int32(decrypted[3])<<24
+	if p != h.Dict.P {
+		return false
+	}
+	if decrypted[4] != 0xFF || decrypted[5] != 0xFF || decrypted[6] != 0xFF || decrypted[7] != 0xFF {
+		return false
+	}
+	wantMetadata := byte('F')
+	if h.Dict.EncryptMetadata {
+		wantMetadata = 'T'
+	}
+	if decrypted[8] != wantMetadata {
+		return false
+	}
+	return decrypted[9] == 'a' && decrypted[10] == 'd' && decrypted[11] == 'b'
+}
+
+// sumModThree returns (sum of bytes) mod 3, used to pick the hash function
+// for the next round of the R6 hardened hash.
+func sumModThree(b []byte) int {
+	sum := 0
+	for _, v := range b {
+		sum += int(v)
+	}
+	return sum % 3
+}
+
+// aesCBCNoPadding decrypts data with AES-256-CBC, a zero IV, and no padding
+// removal — exactly how PDF wraps UE/OE around the file encryption key.
+func aesCBCNoPadding(key, data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("UE/OE length %d is not a multiple of the AES block size", len(data))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	iv := make([]byte, aes.BlockSize)
+	mode := cipher.NewCBCDecrypter(block, iv)
+	out := make([]byte, len(data))
+	mode.CryptBlocks(out, data)
+	return out, nil
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// computeUValue implements Algorithm 4 (R2) / Algorithm 5 (R3/R4): derives
+// the bytes that belong in /U for the handler's current file encryption
+// key, so a candidate password can be verified instead of silently
+// accepted. R3/R4 only pin down the first 16 of /U's 32 bytes; the
+// remainder is implementation-defined padding the spec doesn't require
+// readers to reproduce.
+func (h *EncryptionHandler) computeUValue() []byte {
+	if h.R == 2 {
+		out := make([]byte, len(paddingString))
+		c, err := rc4.NewCipher(h.EncryptKey)
+		if err != nil {
+			return nil
+		}
+		c.XORKeyStream(out, paddingString)
+		return out
+	}
+
+	hash := md5.New()
+	hash.Write(paddingString)
+	hash.Write(h.FileID)
+	digest := hash.Sum(nil)
+
+	c, err := rc4.NewCipher(h.EncryptKey)
+	if err != nil {
+		return nil
+	}
+	c.XORKeyStream(digest, digest)
+
+	for i := byte(1); i <= 19; i++ {
+		roundKey := make([]byte, len(h.EncryptKey))
+		for j := range roundKey {
+			roundKey[j] = h.EncryptKey[j] ^ i
+		}
+		rc, err := rc4.NewCipher(roundKey)
+		if err != nil {
+			return nil
+		}
+		rc.XORKeyStream(digest, digest)
+	}
+	return digest
+}
+
 // computeObjectKey implements Algorithm 1 from PDF spec
 // Derives per-object encryption key from file encryption key
 func (h *EncryptionHandler) computeObjectKey(objNum, genNum int) []byte {
@@ -284,48 +713,113 @@ func removePadding(data []byte) ([]byte, error) {
 	return data[:len(data)-paddingLen], nil
 }
 
-// decryptAES decrypts data using AES-128 in CBC mode
+// decryptAES decrypts data using AES-128 in CBC mode, with a key derived
+// per-object via Algorithm 1.
 func (h *EncryptionHandler) decryptAES(data []byte, objNum, genNum int) ([]byte, error) {
+	return decryptAESCBC(data, h.computeObjectKey(objNum, genNum))
+}
+
+// decryptAES256 decrypts data for R5/R6 (V5) documents, which use the raw
+// 32-byte file encryption key directly instead of deriving a per-object key
+// (there's no object number/generation salting step in Algorithm 1 once R
+// reaches 5 — see ISO 32000-2 7.6.2).
+func (h *EncryptionHandler) decryptAES256(data []byte) ([]byte, error) {
+	return decryptAESCBC(data, h.EncryptKey)
+}
+
+// decryptAESCBC decrypts a PDF string/stream payload whose first 16 bytes
+// are the CBC IV, removing PKCS7 padding from the result.
+func decryptAESCBC(data, key []byte) ([]byte, error) {
 	if len(data) < 16 {
 		return nil, fmt.Errorf("encrypted data too short for AES (need at least 16 bytes for IV, got %d)", len(data))
 	}
 
-	key := h.computeObjectKey(objNum, genNum)
-
-	// First 16 bytes are IV (initialization vector)
 	iv := data[:16]
 	ciphertext := data[16:]
 
-	// Create AES cipher
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
 	}
 
-	// Decrypt using CBC mode
 	mode := cipher.NewCBCDecrypter(block, iv)
 
 	decrypted := make([]byte, len(ciphertext))
 	mode.CryptBlocks(decrypted, ciphertext)
 
-	// Remove PKCS7 padding
 	return removePadding(decrypted)
 }
 
-// Decrypt decrypts data for a specific object using appropriate algorithm
-func (h *EncryptionHandler) Decrypt(data []byte, objNum, genNum int) ([]byte, error) {
+// Decrypt decrypts data for a specific object, using the crypt filter that
+// governs scope (string, stream, or embedded-file) per the document's
+// /StmF, /StrF and /EFF. Use DecryptStream instead when a stream names its
+// own /Crypt filter explicitly, overriding the document default.
+func (h *EncryptionHandler) Decrypt(data []byte, objNum, genNum int, scope CryptFilterScope) ([]byte, error) {
 	if len(data) == 0 {
 		return data, nil
 	}
+	return h.decryptWithCFM(data, objNum, genNum, h.cfmForScope(scope))
+}
+
+// DecryptStream decrypts stream data using filterName (the /Name of the
+// /Crypt filter found in the stream's own /Filter chain, such as a
+// /EmbeddedFile stream with its own /CF entry) instead of the document's
+// default /StmF/EFF.
+func (h *EncryptionHandler) DecryptStream(data []byte, objNum, genNum int, filterName string) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	return h.decryptWithCFM(data, objNum, genNum, h.cfmForFilterName(filterName))
+}
+
+// cfmForScope resolves which crypt filter algorithm governs scope. Documents
+// with no /CF dictionary (V1, V2, and V4/V5 files from writers that skip it)
+// fall back to the single algorithm V has always implied.
+func (h *EncryptionHandler) cfmForScope(scope CryptFilterScope) string {
+	if h.Dict.CF == nil {
+		switch h.V {
+		case 1, 2:
+			return "/V2"
+		case 5:
+			return "/AESV3"
+		default:
+			return "/AESV2"
+		}
+	}
 
-	switch h.V {
-	case 1, 2:
-		// RC4 encryption (40-bit or 128-bit)
+	name := h.Dict.StmF
+	switch scope {
+	case CryptFilterScopeString:
+		name = h.Dict.StrF
+	case CryptFilterScopeEmbeddedFile:
+		name = h.Dict.EFF
+	}
+	return h.cfmForFilterName(name)
+}
+
+// cfmForFilterName looks up the algorithm a named crypt filter uses,
+// treating "/Identity" (and any name /CF doesn't define) as "don't decrypt".
+func (h *EncryptionHandler) cfmForFilterName(name string) string {
+	if name == "" || name == "/Identity" {
+		return "/Identity"
+	}
+	if cf, ok := h.Dict.CF[name]; ok {
+		return cf.CFM
+	}
+	return "/Identity"
+}
+
+func (h *EncryptionHandler) decryptWithCFM(data []byte, objNum, genNum int, cfm string) ([]byte, error) {
+	switch cfm {
+	case "/Identity", "/None":
+		return data, nil
+	case "/V2":
 		return h.decryptRC4(data, objNum, genNum)
-	case 4:
-		// AES-128 encryption
+	case "/AESV2":
 		return h.decryptAES(data, objNum, genNum)
+	case "/AESV3":
+		return h.decryptAES256(data)
 	default:
-		return nil, fmt.Errorf("unsupported encryption version: %d (only V1, V2, V4 are supported)", h.V)
+		return nil, fmt.Errorf("unsupported crypt filter method: %s", cfm)
 	}
 }
@@ -0,0 +1,75 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+// TestComputeEncryptionKeyR6RoundTrip builds a synthetic R6 /U and /UE pair
+// the same way a real PDF writer would - hashR6 for the validation and key
+// hashes, AES-256-CBC with a zero IV to wrap the file key - and confirms
+// computeEncryptionKeyR6 unwraps the original file key given the matching
+// password. This is a round-trip against this package's own hashR6/
+// aesCBCNoPadding rather than an external test vector, mirroring how
+// TestParseXRefHybridXRefStmWins builds its own fixture.
+func TestComputeEncryptionKeyR6RoundTrip(t *testing.T) {
+	password := []byte("hunter2")
+	fileKey := make([]byte, 32)
+	if _, err := rand.Read(fileKey); err != nil {
+		t.Fatalf("generating file key: %v", err)
+	}
+
+	h := &EncryptionHandler{Dict: &EncryptDict{R: 6}}
+
+	validationSalt := make([]byte, 8)
+	keySalt := make([]byte, 8)
+	if _, err := rand.Read(validationSalt); err != nil {
+		t.Fatalf("generating validation salt: %v", err)
+	}
+	if _, err := rand.Read(keySalt); err != nil {
+		t.Fatalf("generating key salt: %v", err)
+	}
+
+	uHash := h.hashR6(password, validationSalt, nil)
+	intermediate := h.hashR6(password, keySalt, nil)
+
+	block, err := aes.NewCipher(intermediate)
+	if err != nil {
+		t.Fatalf("aes.NewCipher: %v", err)
+	}
+	ue := make([]byte, len(fileKey))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(ue, fileKey)
+
+	h.Dict.U = append(append(append([]byte{}, uHash...), validationSalt...), keySalt...)
+	h.Dict.UE = ue
+
+	got, err := h.computeEncryptionKeyR6(password)
+	if err != nil {
+		t.Fatalf("computeEncryptionKeyR6: %v", err)
+	}
+	if !bytes.Equal(got, fileKey) {
+		t.Fatalf("computeEncryptionKeyR6 = %x, want %x", got, fileKey)
+	}
+}
+
+// TestComputeEncryptionKeyR6WrongPassword confirms a password that doesn't
+// validate against /U (and there's no /O to fall back to) is reported as
+// ErrWrongPassword rather than silently producing a garbage key.
+func TestComputeEncryptionKeyR6WrongPassword(t *testing.T) {
+	h := &EncryptionHandler{Dict: &EncryptDict{R: 6}}
+
+	validationSalt := make([]byte, 8)
+	keySalt := make([]byte, 8)
+	uHash := h.hashR6([]byte("correct horse"), validationSalt, nil)
+	h.Dict.U = append(append(append([]byte{}, uHash...), validationSalt...), keySalt...)
+	h.Dict.UE = make([]byte, 32)
+
+	_, err := h.computeEncryptionKeyR6([]byte("wrong password"))
+	if !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("computeEncryptionKeyR6 with wrong password: got %v, want ErrWrongPassword", err)
+	}
+}
@@ -1,11 +1,16 @@
 package pdf
 
 import (
+	"encoding/base64"
+	"fmt"
+	"image/color"
 	"io"
 	"math"
+	"os"
+	"path/filepath"
 	"strings"
 
-	"github.com/AOShei/go-fast-pdf/pkg/model"
+	"github.com/AOShei/pdf-loader/pkg/model"
 )
 
 // Matrix is a 3x3 transform matrix (last row implicitly 0,0,1).
@@ -317,6 +322,54 @@ type Font struct {
 	MissingW   float64         // Default width
 	SpaceWidth float64         // Width of a space character
 	IsCID      bool
+
+	// CID/Type0 font fields, populated from /DescendantFonts when IsCID.
+	CIDEncoding    *CMap   // byte code -> CID, parsed from an embedded /Encoding CMap stream
+	IdentityCID    bool    // /Encoding is /Identity-H or /Identity-V: CID is the raw 2-byte code
+	DW             float64 // default CID width, from /DW (spec default 1000)
+	CIDWidths      map[int]float64
+	CIDSystemInfo  string // "Registry-Ordering", for debugging/diagnostics
+}
+
+// CodeLength returns how many bytes of data make up the next character
+// code for this font: 2 for Identity-H/V, whatever the embedded encoding
+// CMap's codespace ranges say, or 1 for simple (non-CID) fonts.
+func (f *Font) CodeLength(data []byte) int {
+	if f.IdentityCID {
+		if len(data) >= 2 {
+			return 2
+		}
+		return len(data)
+	}
+	if f.CIDEncoding != nil {
+		return f.CIDEncoding.CodeLength(data)
+	}
+	return 1
+}
+
+// CIDFor resolves a raw character code to its CID: the code itself for
+// Identity-H/V, a CIDEncoding lookup for an embedded encoding CMap, or 0
+// for simple fonts (which don't have CIDs at all).
+func (f *Font) CIDFor(code []byte) int {
+	if f.IdentityCID {
+		if len(code) < 2 {
+			return 0
+		}
+		return int(code[0])<<8 | int(code[1])
+	}
+	if f.CIDEncoding != nil {
+		return f.CIDEncoding.CIDForCode(code)
+	}
+	return 0
+}
+
+// WidthForCID returns the glyph width (1/1000 em) for a CID, falling back
+// to the descendant font's /DW default width.
+func (f *Font) WidthForCID(cid int) float64 {
+	if w, ok := f.CIDWidths[cid]; ok {
+		return w
+	}
+	return f.DW
 }
 
 // TextState tracks text-specific parameters.
@@ -357,6 +410,7 @@ type Extractor struct {
 	// Output
 	lastX, lastY float64
 	buffer       strings.Builder
+	glyphs       []Glyph
 
 	// Image tracking
 	images   *[]model.Image // Pointer allows nil (disabled) vs empty slice (enabled, no images)
@@ -388,16 +442,16 @@ func NewExtractor(r *Reader, page DictionaryObject, extractImages bool) (*Extrac
 					objNum = indRef.ObjectNumber
 				}
 
-				fontObj := r.Resolve(ref).(DictionaryObject)
-				e.fonts[name] = e.loadFont(fontObj, objNum) // Pass objNum
+				if fontObj, ok := r.Resolve(ref).(DictionaryObject); ok {
+					e.fonts[name] = e.loadFont(fontObj, objNum)
+				}
 			}
 		}
 
-		// Only load XObject resources if image extraction is enabled
-		if extractImages {
-			if xobjects, ok := r.Resolve(res["/XObject"]).(DictionaryObject); ok {
-				e.xobjects = xobjects
-			}
+		// XObject resources are needed even when image extraction is off,
+		// since Form XObjects may contain text we still need to recurse into.
+		if xobjects, ok := r.Resolve(res["/XObject"]).(DictionaryObject); ok {
+			e.xobjects = xobjects
 		}
 	}
 
@@ -421,26 +475,27 @@ func (e *Extractor) loadFont(obj DictionaryObject, objNum int) *Font {
 		MissingW: 0, // Default usually 0 unless specified
 	}
 
+	v := e.reader.Value(obj)
+
 	// 3. Get BaseFont name (for debugging/fallback)
-	if bf, ok := e.reader.Resolve(obj["/BaseFont"]).(NameObject); ok {
-		f.BaseFont = string(bf)
+	if bf, ok := v.Key("/BaseFont").Name(); ok {
+		f.BaseFont = bf
 	}
 
 	// 4. Parse Widths (Simple Fonts)
 	// PDF defines widths for range FirstChar to LastChar
-	if firstObj, ok := e.reader.Resolve(obj["/FirstChar"]).(NumberObject); ok {
+	if firstObj, ok := v.Key("/FirstChar").Int64(); ok {
 		first := int(firstObj)
-		if widths, ok := e.reader.Resolve(obj["/Widths"]).(ArrayObject); ok {
-			for i, wObj := range widths {
-				if w, ok := wObj.(NumberObject); ok {
-					f.Widths[first+i] = float64(w)
-				}
+		widths := v.Key("/Widths")
+		for i := 0; i < widths.Len(); i++ {
+			if w, ok := widths.Index(i).Float64(); ok {
+				f.Widths[first+i] = w
 			}
 		}
 	} else {
-		// TODO: Handle CIDFonts (Type0) /DescendantFonts which use /W array
-		// For now, we leave Widths empty, handleText will fallback to heuristic
 		f.IsCID = true
+		f.DW = 1000
+		e.loadCIDFont(f, obj)
 	}
 
 	// 5. Determine Space Width (Try char 32, else 250 default)
@@ -451,7 +506,7 @@ func (e *Extractor) loadFont(obj DictionaryObject, objNum int) *Font {
 	}
 
 	// 6. Parse ToUnicode CMap
-	if toUnicode, ok := e.reader.Resolve(obj["/ToUnicode"]).(StreamObject); ok {
+	if toUnicode, ok := v.Key("/ToUnicode").Stream(); ok {
 		if cmap, err := ParseCMap(toUnicode.Data); err == nil {
 			f.CMap = cmap
 		} else {
@@ -459,10 +514,16 @@ func (e *Extractor) loadFont(obj DictionaryObject, objNum int) *Font {
 		}
 	} else {
 		f.CMap = NewCMap() // Empty map, will fallback to encoding
-		// Check if there's an Encoding dictionary
-		if enc, ok := obj["/Encoding"]; ok {
+		// Check if there's an Encoding dictionary (simple fonts only; a
+		// Type0 font's /Encoding was already consumed by loadCIDFont above)
+		if enc, ok := obj["/Encoding"]; ok && !f.IsCID {
 			e.parseEncoding(f, enc)
 		}
+		// No /ToUnicode at all: as a last resort, try to recover a
+		// code->Unicode mapping from the embedded font program itself.
+		if !f.IsCID {
+			e.deriveToUnicodeFromFontProgram(f, obj)
+		}
 	}
 
 	// 7. Save to Global Cache (This is the missing part)
@@ -473,53 +534,165 @@ func (e *Extractor) loadFont(obj DictionaryObject, objNum int) *Font {
 	return f
 }
 
-// parseEncoding parses the /Encoding dictionary and populates the font's encoding map
+// loadCIDFont parses a Type0 font's /DescendantFonts CIDFont dictionary
+// (/DW, /W, /CIDSystemInfo) and its /Encoding (Identity-H/V, or an
+// embedded CMap stream mapping byte codes to CIDs).
+func (e *Extractor) loadCIDFont(f *Font, obj DictionaryObject) {
+	v := e.reader.Value(obj)
+	descArr := v.Key("/DescendantFonts")
+	if descArr.Len() > 0 {
+		cidFont := descArr.Index(0)
+		if dw, ok := cidFont.Key("/DW").Float64(); ok {
+			f.DW = dw
+		}
+		if wArr := cidFont.Key("/W"); wArr.Kind() == KindArray {
+			f.CIDWidths = parseCIDWidths(wArr)
+		}
+		if sysInfo := cidFont.Key("/CIDSystemInfo"); sysInfo.Kind() == KindDictionary {
+			f.CIDSystemInfo = cidSystemInfoString(sysInfo)
+		}
+	}
+
+	enc := v.Key("/Encoding")
+	if name, ok := enc.Name(); ok {
+		if name == "/Identity-H" || name == "/Identity-V" {
+			f.IdentityCID = true
+		}
+	} else if stm, ok := enc.Stream(); ok {
+		if cmap, err := ParseCMap(stm.Data); err == nil {
+			f.CIDEncoding = cmap
+		}
+	}
+}
+
+// parseCIDWidths parses a CIDFont's /W array: a mix of
+// "c [w1 w2 ... wn]" (individual widths for consecutive CIDs starting at
+// c) and "cFirst cLast w" (one width for a whole CID range) groups.
+func parseCIDWidths(arr Value) map[int]float64 {
+	widths := make(map[int]float64)
+	i := 0
+	for i < arr.Len() {
+		firstObj, ok := arr.Index(i).Int64()
+		if !ok {
+			i++
+			continue
+		}
+		first := int(firstObj)
+		i++
+		if i >= arr.Len() {
+			break
+		}
+
+		if subArr := arr.Index(i); subArr.Kind() == KindArray {
+			for j := 0; j < subArr.Len(); j++ {
+				if w, ok := subArr.Index(j).Float64(); ok {
+					widths[first+j] = w
+				}
+			}
+			i++
+			continue
+		}
+
+		lastObj, ok := arr.Index(i).Int64()
+		if !ok {
+			i++
+			continue
+		}
+		last := int(lastObj)
+		i++
+		if i >= arr.Len() {
+			break
+		}
+		if w, ok := arr.Index(i).Float64(); ok {
+			for cid := first; cid <= last; cid++ {
+				widths[cid] = w
+			}
+		}
+		i++
+	}
+	return widths
+}
+
+// cidSystemInfoString renders a /CIDSystemInfo dictionary as
+// "Registry-Ordering" (e.g. "Adobe-Identity"), the conventional shorthand
+// for identifying a CID collection.
+func cidSystemInfoString(d Value) string {
+	reg, _ := d.Key("/Registry").String()
+	ord, _ := d.Key("/Ordering").String()
+	if reg == "" && ord == "" {
+		return ""
+	}
+	return reg + "-" + ord
+}
+
+// applyBaseEncoding seeds f.Encoding from one of the six standard base
+// encodings PDF names (/WinAnsiEncoding, /MacRomanEncoding,
+// /MacExpertEncoding, /StandardEncoding, /Symbol, /ZapfDingbats), leaving
+// f.Encoding untouched if name isn't one of them.
+func applyBaseEncoding(f *Font, name string) {
+	base := baseEncodingByName(name)
+	if base == nil {
+		return
+	}
+	for code, glyph := range base {
+		f.Encoding[code] = glyph
+	}
+}
+
+// parseEncoding parses the /Encoding entry (a bare base-encoding name, or a
+// dictionary with /BaseEncoding and/or /Differences) and populates the
+// font's encoding map.
 func (e *Extractor) parseEncoding(f *Font, encObj Object) {
-	resolved := e.reader.Resolve(encObj)
+	enc := e.reader.Value(encObj)
 
 	// Handle NameObject (built-in encodings like /WinAnsiEncoding, /MacRomanEncoding)
-	if _, ok := resolved.(NameObject); ok {
-		// TODO: Could load standard encoding tables here
+	if name, ok := enc.Name(); ok {
+		applyBaseEncoding(f, name)
 		return
 	}
 
-	// Handle DictionaryObject with /Differences array
-	encDict, ok := resolved.(DictionaryObject)
-	if !ok {
+	// Handle DictionaryObject with /BaseEncoding and/or /Differences
+	if enc.Kind() != KindDictionary {
 		return
 	}
 
+	if base, ok := enc.Key("/BaseEncoding").Name(); ok {
+		applyBaseEncoding(f, base)
+	} else {
+		// Spec default when /BaseEncoding is absent: StandardEncoding.
+		applyBaseEncoding(f, "/StandardEncoding")
+	}
+
 	// Parse /Differences array
 	// Format: [code1 /name1 /name2 ... code2 /name3 ...]
 	// Numbers set the current code, names assign to sequential codes
-	if diff, ok := e.reader.Resolve(encDict["/Differences"]).(ArrayObject); ok {
-		currentCode := 0
-		for _, item := range diff {
-			if num, ok := item.(NumberObject); ok {
-				// Number sets the current code
-				currentCode = int(num)
-			} else if name, ok := item.(NameObject); ok {
-				// Name assigns to current code, then increment
-				glyphName := string(name)
-				f.Encoding[currentCode] = glyphName
-				currentCode++
-			}
+	diff := enc.Key("/Differences")
+	currentCode := 0
+	for i := 0; i < diff.Len(); i++ {
+		item := diff.Index(i)
+		if num, ok := item.Int64(); ok {
+			// Number sets the current code
+			currentCode = int(num)
+		} else if glyphName, ok := item.Name(); ok {
+			// Name assigns to current code, then increment
+			f.Encoding[currentCode] = glyphName
+			currentCode++
 		}
 	}
 }
 
 // ExtractText is the main entry point.
 func (e *Extractor) ExtractText() (string, error) {
-	contents := e.reader.Resolve(e.page["/Contents"])
+	contents := e.reader.Value(e.page["/Contents"])
 	var streams []StreamObject
 
-	if arr, ok := contents.(ArrayObject); ok {
-		for _, ref := range arr {
-			if s, ok := e.reader.Resolve(ref).(StreamObject); ok {
+	if contents.Kind() == KindArray {
+		for i := 0; i < contents.Len(); i++ {
+			if s, ok := contents.Index(i).Stream(); ok {
 				streams = append(streams, s)
 			}
 		}
-	} else if s, ok := contents.(StreamObject); ok {
+	} else if s, ok := contents.Stream(); ok {
 		streams = append(streams, s)
 	}
 
@@ -540,6 +713,42 @@ func (e *Extractor) ExtractText() (string, error) {
 	return e.buffer.String(), nil
 }
 
+// Glyph is one decoded unit of text (a character, or a CID font's notion of
+// one code) positioned in page space. Positions and widths are approximate:
+// like the rest of this extractor, within-string advances are tracked along
+// the page x-axis only, so a rotated or sheared CTM will not be reflected
+// here.
+type Glyph struct {
+	Text   string  `json:"text"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ExtractTextWithLayout walks the page content streams like ExtractText, but
+// also returns the per-glyph positions and bounding boxes it computed along
+// the way, so callers needing layout (columns, lines, tables) don't have to
+// re-derive it from the plain-text output.
+func (e *Extractor) ExtractTextWithLayout() ([]Glyph, error) {
+	if _, err := e.ExtractText(); err != nil {
+		return nil, err
+	}
+	return e.glyphs, nil
+}
+
+// ExtractTextLayout is the layout-aware counterpart to ExtractText: it walks
+// the page once via ExtractTextWithLayout and feeds the resulting glyphs
+// through ReconstructReadingOrder, for callers that want column/line-aware
+// text without chaining the two calls themselves.
+func (e *Extractor) ExtractTextLayout() (string, error) {
+	glyphs, err := e.ExtractTextWithLayout()
+	if err != nil {
+		return "", err
+	}
+	return ReconstructReadingOrder(glyphs), nil
+}
+
 func (e *Extractor) processOp(op Operation) {
 	switch op.Operator {
 	case "q":
@@ -618,19 +827,22 @@ func (e *Extractor) processOp(op Operation) {
 		e.processOp(Operation{Operator: "T*"})
 		e.processOp(Operation{Operator: "Tj", Operands: op.Operands[2:]})
 	case "INLINE_IMAGE":
-		// Handle inline image placeholder (only if extraction enabled)
+		// Handle inline image (only if extraction enabled)
 		if e.images != nil {
 			if len(op.Operands) > 0 {
 				if dict, ok := op.Operands[0].(DictionaryObject); ok {
-					e.recordInlineImage(dict)
+					e.recordInlineImage(dict, op.InlineData)
 				}
 			}
 		}
 	case "Do":
-		// Handle XObject (image) reference (only if extraction enabled)
-		if e.images != nil {
-			if len(op.Operands) > 0 {
-				if name, ok := op.Operands[0].(NameObject); ok {
+		// Handle XObject reference: recurse into Form XObjects for text
+		// regardless of image extraction, and record images separately
+		// (only if extraction is enabled).
+		if len(op.Operands) > 0 {
+			if name, ok := op.Operands[0].(NameObject); ok {
+				e.processFormText(string(name))
+				if e.images != nil {
 					e.recordImage(string(name))
 				}
 			}
@@ -746,7 +958,31 @@ func (e *Extractor) handleText(obj Object) {
 	// 4. Calculate total width of this string to update lastX
 	totalWidth := 0.0
 
-	if e.textState.Font != nil && len(e.textState.Font.Widths) > 0 {
+	if e.textState.Font != nil && e.textState.Font.IsCID {
+		// CID font: codes aren't necessarily 1 byte each, so walk them
+		// using the encoding CMap's codespace widths and look widths up
+		// by CID (/W array / /DW default) instead of by raw byte.
+		glyphCount := 0
+		for i := 0; i < len(rawBytes); {
+			n := e.textState.Font.CodeLength(rawBytes[i:])
+			if n <= 0 || i+n > len(rawBytes) {
+				n = len(rawBytes) - i
+			}
+			cid := e.textState.Font.CIDFor(rawBytes[i : i+n])
+			totalWidth += e.textState.Font.WidthForCID(cid)
+			glyphCount++
+			i += n
+		}
+		totalWidth = (totalWidth / 1000.0) * e.textState.FontSize
+		totalWidth += float64(glyphCount) * e.textState.CharSpacing
+
+		decodedStr := decoded.String()
+		spaceCount := strings.Count(decodedStr, " ")
+		totalWidth += float64(spaceCount) * e.textState.WordSpacing
+
+		totalWidth *= (e.textState.Scale / 100.0)
+
+	} else if e.textState.Font != nil && len(e.textState.Font.Widths) > 0 {
 		// Use Widths Map
 		for _, b := range rawBytes {
 			code := int(b)
@@ -781,6 +1017,8 @@ func (e *Extractor) handleText(obj Object) {
 		totalWidth = float64(decoded.Len()) * e.textState.FontSize * 0.5 * (e.textState.Scale / 100.0)
 	}
 
+	e.recordGlyphs(rawBytes, x, y)
+
 	e.lastX = x + totalWidth
 	e.lastY = y
 
@@ -789,35 +1027,159 @@ func (e *Extractor) handleText(obj Object) {
 	e.textState.TM[5] += totalWidth * e.textState.TM[1]
 }
 
+// recordGlyphs appends one Glyph per decoded code unit in rawBytes, starting
+// at page-space position (x, y). It mirrors the decode and width priority
+// rules above (CMap, then Encoding, then a raw-byte fallback; CID /W array,
+// then the simple-font Widths map, then the 0.5em heuristic) but walks them
+// per code instead of per whole string, so it's kept separate from the bulk
+// decode/width passes above rather than folded into them.
+func (e *Extractor) recordGlyphs(rawBytes []byte, x, y float64) {
+	f := e.textState.Font
+	scale := e.textState.Scale / 100.0
+	height := e.textState.FontSize * scale
+	cursor := x
+
+	i := 0
+	for i < len(rawBytes) {
+		n := 1
+		if f != nil && f.IsCID {
+			if cl := f.CodeLength(rawBytes[i:]); cl > 0 {
+				n = cl
+			}
+		}
+		if i+n > len(rawBytes) {
+			n = len(rawBytes) - i
+		}
+		code := rawBytes[i : i+n]
+		i += n
+
+		var text string
+		switch {
+		case f != nil && f.CMap != nil && len(f.CMap.Map) > 0:
+			if n == 2 {
+				text = f.CMap.Map[string(code)]
+			}
+			if text == "" {
+				if val, ok := f.CMap.Map[string(code[:1])]; ok {
+					text = val
+				} else {
+					text = string(code[0])
+				}
+			}
+		case f != nil && len(f.Encoding) > 0 && n == 1:
+			if glyphName, ok := f.Encoding[int(code[0])]; ok {
+				if unicode, ok := glyphToUnicode[glyphName]; ok {
+					text = unicode
+				} else if len(glyphName) == 2 && glyphName[0] == '/' {
+					text = string(glyphName[1])
+				} else {
+					text = string(code[0])
+				}
+			} else {
+				text = string(code[0])
+			}
+		default:
+			text = filterControlChars(code)
+		}
+		if text == "" {
+			continue
+		}
+
+		var adv float64
+		switch {
+		case f != nil && f.IsCID:
+			w := f.WidthForCID(f.CIDFor(code))
+			adv = (w/1000.0)*e.textState.FontSize + e.textState.CharSpacing
+			if text == " " {
+				adv += e.textState.WordSpacing
+			}
+			adv *= scale
+		case f != nil && len(f.Widths) > 0 && n == 1:
+			w := f.MissingW
+			if val, ok := f.Widths[int(code[0])]; ok {
+				w = val
+			}
+			adv = (w/1000.0)*e.textState.FontSize + e.textState.CharSpacing
+			if text == " " {
+				adv += e.textState.WordSpacing
+			}
+			adv *= scale
+		default:
+			adv = e.textState.FontSize * 0.5 * scale
+		}
+
+		e.glyphs = append(e.glyphs, Glyph{Text: text, X: cursor, Y: y, Width: adv, Height: height})
+		cursor += adv
+	}
+}
+
 // recordInlineImage records an inline image placeholder
-func (e *Extractor) recordInlineImage(dict DictionaryObject) {
+func (e *Extractor) recordInlineImage(dict DictionaryObject, data []byte) {
 	img := model.Image{
 		Type: "inline_image",
 		Rect: e.calculateImageRect(),
 	}
 
 	// Extract metadata from inline image dictionary
-	if w, ok := dict["/W"].(NumberObject); ok {
-		img.Width = float64(w)
-	} else if w, ok := dict["/Width"].(NumberObject); ok {
-		img.Width = float64(w)
+	v := e.reader.Value(dict)
+	if w, ok := v.Key("/W").Float64(); ok {
+		img.Width = w
+	} else if w, ok := v.Key("/Width").Float64(); ok {
+		img.Width = w
 	}
 
-	if h, ok := dict["/H"].(NumberObject); ok {
-		img.Height = float64(h)
-	} else if h, ok := dict["/Height"].(NumberObject); ok {
-		img.Height = float64(h)
+	if h, ok := v.Key("/H").Float64(); ok {
+		img.Height = h
+	} else if h, ok := v.Key("/Height").Float64(); ok {
+		img.Height = h
 	}
 
-	if cs, ok := dict["/CS"].(NameObject); ok {
-		img.ColorSpace = string(cs)
-	} else if cs, ok := dict["/ColorSpace"].(NameObject); ok {
-		img.ColorSpace = string(cs)
+	cs := v.Key("/CS")
+	if cs.Kind() == KindInvalid {
+		cs = v.Key("/ColorSpace")
 	}
+	e.populateImageColorSpace(&img, cs)
+
+	if bpc, ok := v.Key("/BPC").Int64(); ok {
+		img.BitsPerComponent = int(bpc)
+	} else if bpc, ok := v.Key("/BitsPerComponent").Int64(); ok {
+		img.BitsPerComponent = int(bpc)
+	}
+
+	if im, ok := v.Key("/IM").Bool(); ok {
+		img.IsMask = im
+	} else if im, ok := v.Key("/ImageMask").Bool(); ok {
+		img.IsMask = im
+	}
+
+	e.populateInlineImageBitmap(&img, dict, data)
 
 	*e.images = append(*e.images, img)
 }
 
+// populateInlineImageBitmap decodes an inline image's raw sample bytes the
+// same way populateImageBitmap does for an Image XObject, except the filter
+// chain has to be run here - unlike an XObject stream, data hasn't already
+// passed through DecodeChain - and the dictionary keys are inline images'
+// abbreviated forms (/F, /DP) falling back to the full names.
+func (e *Extractor) populateInlineImageBitmap(img *model.Image, dict DictionaryObject, data []byte) {
+	filterObj, ok := dict["/F"]
+	if !ok {
+		filterObj = dict["/Filter"]
+	}
+	parmsObj, ok := dict["/DP"]
+	if !ok {
+		parmsObj = dict["/DecodeParms"]
+	}
+
+	decoded, err := DecodeChain(data, filterObj, parmsObj)
+	if err != nil {
+		return
+	}
+	img.Data = base64.StdEncoding.EncodeToString(decoded)
+	img.Format = imageFormat(e.reader.Value(filterObj))
+}
+
 // recordImage records an XObject image reference
 func (e *Extractor) recordImage(name string) {
 	if e.xobjects == nil {
@@ -839,14 +1201,15 @@ func (e *Extractor) recordImage(name string) {
 	}
 
 	// Check the subtype - can be /Image or /Form
-	if subtype, ok := e.reader.Resolve(xobjDict["/Subtype"]).(NameObject); ok {
-		if string(subtype) == "/Form" {
+	v := e.reader.Value(xobjDict)
+	if subtype, ok := v.Key("/Subtype").Name(); ok {
+		if subtype == "/Form" {
 			// Form XObjects contain nested content streams that may reference images
 			e.processFormXObject(xobj)
 			return
 		}
 
-		if string(subtype) != "/Image" {
+		if subtype != "/Image" {
 			return
 		}
 	} else {
@@ -860,19 +1223,120 @@ func (e *Extractor) recordImage(name string) {
 	}
 
 	// Extract image metadata
-	if w, ok := e.reader.Resolve(xobjDict["/Width"]).(NumberObject); ok {
-		img.Width = float64(w)
+	if w, ok := v.Key("/Width").Float64(); ok {
+		img.Width = w
 	}
-	if h, ok := e.reader.Resolve(xobjDict["/Height"]).(NumberObject); ok {
-		img.Height = float64(h)
+	if h, ok := v.Key("/Height").Float64(); ok {
+		img.Height = h
 	}
-	if cs, ok := e.reader.Resolve(xobjDict["/ColorSpace"]).(NameObject); ok {
-		img.ColorSpace = string(cs)
+	if bpc, ok := v.Key("/BitsPerComponent").Int64(); ok {
+		img.BitsPerComponent = int(bpc)
 	}
+	e.populateImageColorSpace(&img, v.Key("/ColorSpace"))
+	e.populateImageMasks(&img, xobjDict)
+	e.populateImageBitmap(&img, xobj)
+	populateObjectFit(&img)
 
 	*e.images = append(*e.images, img)
 }
 
+// maxFormRecursionDepth bounds runFormText's recursion in case a malformed
+// (or adversarial) file sets up a Form XObject that invokes itself.
+const maxFormRecursionDepth = 16
+
+// processFormText looks up name in the current XObject resources and, if it
+// is a Form XObject, recurses into its content stream for text.
+func (e *Extractor) processFormText(name string) {
+	if e.xobjects == nil {
+		return
+	}
+	xobj := e.reader.Resolve(e.xobjects[name])
+	e.runFormText(xobj, 0)
+}
+
+// runFormText recurses into a Form XObject's content stream so that text
+// drawn inside it (headers/footers, repeated boilerplate, form-field
+// appearances, etc.) is captured, not just the page's top-level content.
+// It concatenates the form's /Matrix into the CTM and makes the form's own
+// /Resources (fonts and nested XObjects) visible for the duration, the same
+// way a 'q'/'Q' pair brackets any other nested graphics state.
+//
+// Nested Do operators for images are intentionally left alone here -
+// recordImage/processFormXObject already walk forms looking for images
+// when image extraction is enabled, so handling them again in this pass
+// would double-count them.
+func (e *Extractor) runFormText(xobj Object, depth int) {
+	if depth > maxFormRecursionDepth {
+		return
+	}
+	streamObj, ok := xobj.(StreamObject)
+	if !ok {
+		return
+	}
+	formValue := e.reader.Value(streamObj.Dictionary)
+	if subtype, ok := formValue.Key("/Subtype").Name(); !ok || subtype != "/Form" {
+		return
+	}
+
+	e.gStack = append(e.gStack, e.gState)
+	if m := formValue.Key("/Matrix"); m.Len() == 6 {
+		var formMatrix Matrix
+		for i := range formMatrix {
+			if n, ok := m.Index(i).Float64(); ok {
+				formMatrix[i] = n
+			}
+		}
+		e.gState.CTM = formMatrix.Mult(e.gState.CTM)
+	}
+
+	savedFonts, savedXObjects := e.fonts, e.xobjects
+	e.fonts = make(map[string]*Font, len(savedFonts))
+	for k, v := range savedFonts {
+		e.fonts[k] = v
+	}
+	e.xobjects = nil
+	if res, ok := e.reader.Resolve(streamObj.Dictionary["/Resources"]).(DictionaryObject); ok {
+		if fonts, ok := e.reader.Resolve(res["/Font"]).(DictionaryObject); ok {
+			for fname, ref := range fonts {
+				var objNum int
+				if indRef, ok := ref.(IndirectObject); ok {
+					objNum = indRef.ObjectNumber
+				}
+				if fontObj, ok := e.reader.Resolve(ref).(DictionaryObject); ok {
+					e.fonts[fname] = e.loadFont(fontObj, objNum)
+				}
+			}
+		}
+		if xo, ok := e.reader.Resolve(res["/XObject"]).(DictionaryObject); ok {
+			e.xobjects = xo
+		}
+	}
+
+	parser := NewContentStreamParser(streamObj.Data)
+	for {
+		op, err := parser.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			break
+		}
+		if op.Operator == "Do" && len(op.Operands) > 0 {
+			if nestedName, ok := op.Operands[0].(NameObject); ok && e.xobjects != nil {
+				if nested := e.reader.Resolve(e.xobjects[string(nestedName)]); nested != nil {
+					e.runFormText(nested, depth+1)
+				}
+			}
+			continue
+		}
+		e.processOp(*op)
+	}
+
+	e.fonts, e.xobjects = savedFonts, savedXObjects
+	e.gState = e.gStack[len(e.gStack)-1]
+	e.gStack = e.gStack[:len(e.gStack)-1]
+}
+
 // processFormXObject recursively processes a Form XObject to find nested images
 // Removed 'name' parameter as it was unused
 func (e *Extractor) processFormXObject(xobj Object) {
@@ -907,11 +1371,9 @@ func (e *Extractor) processFormXObject(xobj Object) {
 
 				// Get the nested XObject from the form's resources
 				if formResources != nil {
-					if nestedXObjects, ok := e.reader.Resolve(formResources["/XObject"]).(DictionaryObject); ok {
-						if nestedXObj := e.reader.Resolve(nestedXObjects[string(imgName)]); nestedXObj != nil {
-							// Recursively process this XObject
-							e.recordNestedImage(string(imgName), nestedXObj)
-						}
+					if nestedXObj := e.reader.Value(formResources).Key("/XObject").Key(string(imgName)); nestedXObj.Kind() != KindInvalid {
+						// Recursively process this XObject
+						e.recordNestedImage(string(imgName), nestedXObj.resolved())
 					}
 				}
 			}
@@ -933,15 +1395,16 @@ func (e *Extractor) recordNestedImage(name string, xobj Object) {
 	}
 
 	// Check subtype
-	if subtype, ok := e.reader.Resolve(xobjDict["/Subtype"]).(NameObject); ok {
+	v := e.reader.Value(xobjDict)
+	if subtype, ok := v.Key("/Subtype").Name(); ok {
 
-		if string(subtype) == "/Form" {
+		if subtype == "/Form" {
 			// Another nested form - recurse
 			e.processFormXObject(xobj)
 			return
 		}
 
-		if string(subtype) != "/Image" {
+		if subtype != "/Image" {
 			return
 		}
 	} else {
@@ -955,32 +1418,281 @@ func (e *Extractor) recordNestedImage(name string, xobj Object) {
 		Rect: e.calculateImageRect(),
 	}
 
-	if w, ok := e.reader.Resolve(xobjDict["/Width"]).(NumberObject); ok {
-		img.Width = float64(w)
+	if w, ok := v.Key("/Width").Float64(); ok {
+		img.Width = w
 	}
-	if h, ok := e.reader.Resolve(xobjDict["/Height"]).(NumberObject); ok {
-		img.Height = float64(h)
+	if h, ok := v.Key("/Height").Float64(); ok {
+		img.Height = h
 	}
-	if cs, ok := e.reader.Resolve(xobjDict["/ColorSpace"]).(NameObject); ok {
-		img.ColorSpace = string(cs)
+	if bpc, ok := v.Key("/BitsPerComponent").Int64(); ok {
+		img.BitsPerComponent = int(bpc)
 	}
+	e.populateImageColorSpace(&img, v.Key("/ColorSpace"))
+	e.populateImageMasks(&img, xobjDict)
+	e.populateImageBitmap(&img, xobj)
+	populateObjectFit(&img)
 
 	*e.images = append(*e.images, img)
 }
 
-// calculateImageRect calculates the bounding box of an image using current CTM
+// nativeDPI is the resolution at which a PDF image is considered "native":
+// the PDF unit is defined as 1/72 inch, so one source pixel per unit (i.e.
+// Rect's width in points equal to Width in pixels) works out to 72 pixels
+// per inch.
+const nativeDPI = 72.0
+
+// objectFitTolerance is the relative difference below which a ratio
+// comparison (aspect ratio, DPI) is treated as drawing jitter rather than an
+// intentional scale/distortion.
+const objectFitTolerance = 0.01
+
+// populateObjectFit fills in an image's intrinsic aspect ratio and flags
+// whether its placement Rect distorts that ratio, mirroring the CSS
+// object-fit question: would drawing the source pixels to exactly fill Rect
+// ("fill"/stretch) look different from preserving the aspect ratio
+// ("contain"/"cover")? It also sets EffectiveDPIX/Y, DistortionScore and
+// Fit. Images with no positive Width/Height or Rect size are left as is.
+func populateObjectFit(img *model.Image) {
+	if img.Width <= 0 || img.Height <= 0 || len(img.Rect) != 4 || img.Rect[2] <= 0 || img.Rect[3] <= 0 {
+		return
+	}
+	img.AspectRatio = img.Width / img.Height
+
+	placedRatio := img.Rect[2] / img.Rect[3]
+	img.Stretched = math.Abs(placedRatio-img.AspectRatio) > img.AspectRatio*objectFitTolerance
+
+	img.EffectiveDPIX = img.Width * nativeDPI / img.Rect[2]
+	img.EffectiveDPIY = img.Height * nativeDPI / img.Rect[3]
+
+	maxDPI := math.Max(img.EffectiveDPIX, img.EffectiveDPIY)
+	if maxDPI > 0 {
+		img.DistortionScore = math.Abs(img.EffectiveDPIX-img.EffectiveDPIY) / maxDPI
+	}
+
+	switch {
+	case img.Stretched:
+		img.Fit = "stretch"
+	case math.Abs(img.EffectiveDPIX-nativeDPI) <= nativeDPI*objectFitTolerance:
+		img.Fit = "native"
+	case img.EffectiveDPIX > nativeDPI:
+		img.Fit = "contain" // more source detail than Rect needs: scaled down uniformly
+	default:
+		img.Fit = "cover" // less source detail than Rect displays: scaled up uniformly
+	}
+}
+
+// populateImageMasks records whether xobjDict is itself a stencil mask
+// (/ImageMask) and resolves its /Mask and /SMask entries: /SMask and an
+// explicit-image /Mask become nested Images (recursing through the same
+// metadata/bitmap extraction), while an array /Mask is color-key masking,
+// which has no image of its own to attach.
+func (e *Extractor) populateImageMasks(img *model.Image, xobjDict DictionaryObject) {
+	v := e.reader.Value(xobjDict)
+	if im, ok := v.Key("/ImageMask").Bool(); ok {
+		img.IsMask = im
+	}
+
+	if v.Key("/Mask").Kind() == KindArray {
+		img.HasColorKeyMask = true
+	} else {
+		img.Mask = e.buildMaskImage(xobjDict, "/Mask")
+	}
+
+	img.SMask = e.buildMaskImage(xobjDict, "/SMask")
+	if ref, ok := xobjDict["/SMask"].(IndirectObject); ok {
+		img.SMaskID = ref.String()
+	}
+}
+
+// buildMaskImage resolves parent[key] and, if it's a stream (an explicit
+// mask or soft-mask image), extracts its metadata and bitmap the same way
+// recordImage does for a regular Image XObject.
+func (e *Extractor) buildMaskImage(parent DictionaryObject, key string) *model.Image {
+	stream, ok := e.reader.Value(parent).Key(key).Stream()
+	if !ok {
+		return nil
+	}
+
+	img := &model.Image{Type: "image"}
+	dict := e.reader.Value(stream.Dictionary)
+	if w, ok := dict.Key("/Width").Float64(); ok {
+		img.Width = w
+	}
+	if h, ok := dict.Key("/Height").Float64(); ok {
+		img.Height = h
+	}
+	if bpc, ok := dict.Key("/BitsPerComponent").Int64(); ok {
+		img.BitsPerComponent = int(bpc)
+	}
+	e.populateImageColorSpace(img, dict.Key("/ColorSpace"))
+	if im, ok := dict.Key("/ImageMask").Bool(); ok {
+		img.IsMask = im
+	}
+	e.populateImageBitmap(img, stream)
+	return img
+}
+
+// populateImageBitmap fills in the decoded bitmap bytes and a best-effort
+// format tag for an Image XObject, reusing whatever filter decoding
+// DecodeChain already applied when the stream was first read. DCTDecode/
+// CCITTFaxDecode/JBIG2Decode/JPXDecode are passed through by DecodeChain as
+// their native encoded bytes, so "jpeg"/"ccitt"/"jbig2"/"jpx" data is usable
+// as-is; anything else comes out as raw, unpacked sample data.
+func (e *Extractor) populateImageBitmap(img *model.Image, xobj Object) {
+	stream, ok := xobj.(StreamObject)
+	if !ok {
+		return
+	}
+	img.Data = base64.StdEncoding.EncodeToString(stream.Data)
+	img.Format = imageFormat(e.reader.Value(stream.Dictionary).Key("/Filter"))
+}
+
+// imageFormat maps a stream's /Filter (or the last filter in a chain) to the
+// encoding of the bytes DecodeChain leaves behind.
+func imageFormat(filter Value) string {
+	var name string
+	if n, ok := filter.Name(); ok {
+		name = n
+	} else if filter.Kind() == KindArray && filter.Len() > 0 {
+		if n, ok := filter.Index(filter.Len() - 1).Name(); ok {
+			name = n
+		}
+	}
+	switch name {
+	case "/DCTDecode", "/DCT":
+		return "jpeg"
+	case "/JPXDecode":
+		return "jpx"
+	case "/CCITTFaxDecode", "/CCF":
+		return "ccitt"
+	case "/JBIG2Decode":
+		return "jbig2"
+	default:
+		return "raw"
+	}
+}
+
+// populateImageColorSpace sets img.ColorSpace from cs, and - for an /Indexed
+// colorspace - also decodes the lookup table into img.Palette. A plain name
+// (/DeviceRGB etc.) is stored as-is; an array colorspace ([/Indexed base
+// hival lookup], [/ICCBased stream], [/Separation ...], ...) previously made
+// the caller's v.Key("/ColorSpace").Name() fail silently, so this stores the
+// array's family name (its first element) instead of leaving ColorSpace
+// empty.
+func (e *Extractor) populateImageColorSpace(img *model.Image, cs Value) {
+	if name, ok := cs.Name(); ok {
+		img.ColorSpace = name
+		return
+	}
+	if cs.Kind() != KindArray || cs.Len() == 0 {
+		return
+	}
+	family, ok := cs.Index(0).Name()
+	if !ok {
+		return
+	}
+	img.ColorSpace = family
+	if family != "/Indexed" || cs.Len() < 4 {
+		return
+	}
+	img.Palette = decodeIndexedPalette(cs.Index(1), cs.Index(3))
+}
+
+// decodeIndexedPalette decodes an /Indexed colorspace's lookup table (a
+// string or stream of packed base-space component bytes, one entry per
+// index) into a color.Color per entry, using base to figure out how many
+// bytes each entry takes. Base colorspaces this package can't interpret
+// (e.g. a /Separation or /DeviceN base) yield no palette rather than a
+// guess.
+func decodeIndexedPalette(base, lookup Value) []color.Color {
+	n := baseColorSpaceComponents(base)
+	if n == 0 {
+		return nil
+	}
+
+	var data []byte
+	if s, ok := lookup.String(); ok {
+		data = []byte(s)
+	} else if stream, ok := lookup.Stream(); ok {
+		data = stream.Data
+	} else {
+		return nil
+	}
+
+	entries := len(data) / n
+	palette := make([]color.Color, 0, entries)
+	for i := 0; i < entries; i++ {
+		comp := data[i*n : i*n+n]
+		switch n {
+		case 1:
+			palette = append(palette, color.Gray{Y: comp[0]})
+		case 3:
+			palette = append(palette, color.RGBA{R: comp[0], G: comp[1], B: comp[2], A: 0xFF})
+		case 4:
+			palette = append(palette, color.CMYK{C: comp[0], M: comp[1], Y: comp[2], K: comp[3]})
+		}
+	}
+	return palette
+}
+
+// baseColorSpaceComponents returns how many bytes one /Indexed palette entry
+// takes under base, or 0 if base isn't a colorspace this package knows how
+// to decode a palette for.
+func baseColorSpaceComponents(base Value) int {
+	if name, ok := base.Name(); ok {
+		switch name {
+		case "/DeviceGray", "/CalGray":
+			return 1
+		case "/DeviceRGB", "/CalRGB":
+			return 3
+		case "/DeviceCMYK":
+			return 4
+		}
+		return 0
+	}
+	if base.Kind() == KindArray && base.Len() > 0 {
+		if family, ok := base.Index(0).Name(); ok && family == "/ICCBased" {
+			if stream, ok := base.Index(1).Stream(); ok {
+				if n, ok := base.Reader().Value(stream.Dictionary).Key("/N").Int64(); ok {
+					return int(n)
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// calculateImageRect calculates the axis-aligned bounding box of an image
+// using the current CTM. In PDF, images are drawn in the unit square (0,0)
+// to (1,1); the CTM maps that square to a parallelogram on the page, which
+// is only ever an upright rectangle when the CTM has no rotation or shear.
+// We transform all four corners and take their min/max rather than just
+// scaling the basis vectors, so a rotated, sheared, or negatively-scaled
+// (flipped) CTM still yields a correct enclosing rect.
 func (e *Extractor) calculateImageRect() []float64 {
-	// In PDF, images are drawn in a unit square (0,0) to (1,1)
-	// The CTM transforms this to the actual position/size on the page
 	ctm := e.gState.CTM
 
-	// Transform corners of unit square
-	x := ctm[4]
-	y := ctm[5]
-	width := math.Sqrt(ctm[0]*ctm[0] + ctm[1]*ctm[1])
-	height := math.Sqrt(ctm[2]*ctm[2] + ctm[3]*ctm[3])
+	corners := [4][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range corners {
+		x := c[0]*ctm[0] + c[1]*ctm[2] + ctm[4]
+		y := c[0]*ctm[1] + c[1]*ctm[3] + ctm[5]
+		if x < minX {
+			minX = x
+		}
+		if x > maxX {
+			maxX = x
+		}
+		if y < minY {
+			minY = y
+		}
+		if y > maxY {
+			maxY = y
+		}
+	}
 
-	return []float64{x, y, width, height}
+	return []float64{minX, minY, maxX - minX, maxY - minY}
 }
 
 // GetImages returns the images found on this page
@@ -988,6 +1700,78 @@ func (e *Extractor) GetImages() *[]model.Image {
 	return e.images
 }
 
+// ImagesAtDPI returns the extracted images whose effective resolution (the
+// lower of EffectiveDPIX/EffectiveDPIY, i.e. whichever axis has the least
+// source detail) falls below targetDPI - the images that would look soft or
+// pixelated if the page were rendered/printed at targetDPI. Images
+// populateObjectFit couldn't compute a DPI for (zero Width/Height/Rect) are
+// excluded rather than guessed at.
+func (e *Extractor) ImagesAtDPI(targetDPI float64) []model.Image {
+	if e.images == nil {
+		return nil
+	}
+	var out []model.Image
+	for _, img := range *e.images {
+		if img.EffectiveDPIX <= 0 || img.EffectiveDPIY <= 0 {
+			continue
+		}
+		if math.Min(img.EffectiveDPIX, img.EffectiveDPIY) < targetDPI {
+			out = append(out, img)
+		}
+	}
+	return out
+}
+
+// SaveImages decodes every extracted image's base64 Data and writes it to
+// dir as its own file, returning the paths written in extraction order.
+// Images with no captured Data (a Do reference that never reached a
+// readable stream, or an inline image whose filter chain couldn't be
+// decoded) are skipped rather than failing the whole page.
+func (e *Extractor) SaveImages(dir string) ([]string, error) {
+	if e.images == nil {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("SaveImages: %w", err)
+	}
+
+	var paths []string
+	for i, img := range *e.images {
+		if img.Data == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(img.Data)
+		if err != nil {
+			return paths, fmt.Errorf("SaveImages: decoding image %d: %w", i, err)
+		}
+		name := img.ID
+		if name == "" {
+			name = fmt.Sprintf("inline%d", i)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%03d_%s%s", i, name, imageExtension(img.Format)))
+		if err := os.WriteFile(path, raw, 0o644); err != nil {
+			return paths, fmt.Errorf("SaveImages: writing %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// imageExtension maps a model.Image's Format tag to the file extension its
+// decoded bytes should be saved with.
+func imageExtension(format string) string {
+	switch format {
+	case "jpeg":
+		return ".jpg"
+	case "jpx":
+		return ".jpx"
+	case "ccitt":
+		return ".ccitt"
+	default:
+		return ".bin"
+	}
+}
+
 // Helpers
 
 func number(o Object) float64 {
@@ -0,0 +1,76 @@
+package pdf
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/AOShei/pdf-loader/pkg/model"
+)
+
+// TestPopulateImageColorSpaceName checks the plain-name case still works as
+// before: ColorSpace is set straight from the NameObject.
+func TestPopulateImageColorSpaceName(t *testing.T) {
+	e := &Extractor{}
+	var img model.Image
+	e.populateImageColorSpace(&img, Value{obj: NameObject("/DeviceRGB")})
+	if img.ColorSpace != "/DeviceRGB" {
+		t.Fatalf("ColorSpace = %q, want /DeviceRGB", img.ColorSpace)
+	}
+}
+
+// TestPopulateImageColorSpaceIndexedDecodesPalette is a regression test for
+// v.Key("/ColorSpace").Name() silently failing on an array colorspace: an
+// /Indexed [/DeviceRGB hival lookup] array must set ColorSpace to the
+// family name and decode the lookup string into one color.Color per entry.
+func TestPopulateImageColorSpaceIndexedDecodesPalette(t *testing.T) {
+	lookup := StringObject(string([]byte{
+		0xFF, 0x00, 0x00, // index 0: red
+		0x00, 0xFF, 0x00, // index 1: green
+		0x00, 0x00, 0xFF, // index 2: blue
+	}))
+	cs := ArrayObject{
+		NameObject("/Indexed"),
+		NameObject("/DeviceRGB"),
+		NumberObject(2),
+		lookup,
+	}
+
+	e := &Extractor{}
+	var img model.Image
+	e.populateImageColorSpace(&img, Value{obj: cs})
+
+	if img.ColorSpace != "/Indexed" {
+		t.Fatalf("ColorSpace = %q, want /Indexed", img.ColorSpace)
+	}
+	if len(img.Palette) != 3 {
+		t.Fatalf("len(Palette) = %d, want 3", len(img.Palette))
+	}
+	want := []color.RGBA{
+		{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+		{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF},
+		{R: 0x00, G: 0x00, B: 0xFF, A: 0xFF},
+	}
+	for i, w := range want {
+		if img.Palette[i] != w {
+			t.Fatalf("Palette[%d] = %v, want %v", i, img.Palette[i], w)
+		}
+	}
+}
+
+// TestPopulateImageColorSpaceUnknownArrayFamily checks a colorspace family
+// this package can't decode a palette for (e.g. /Separation) still at least
+// records the family name instead of leaving ColorSpace empty.
+func TestPopulateImageColorSpaceUnknownArrayFamily(t *testing.T) {
+	cs := ArrayObject{NameObject("/Separation"), NameObject("/All"), NameObject("/DeviceGray")}
+
+	e := &Extractor{}
+	var img model.Image
+	e.populateImageColorSpace(&img, Value{obj: cs})
+
+	if img.ColorSpace != "/Separation" {
+		t.Fatalf("ColorSpace = %q, want /Separation", img.ColorSpace)
+	}
+	if img.Palette != nil {
+		t.Fatalf("Palette = %v, want nil", img.Palette)
+	}
+}
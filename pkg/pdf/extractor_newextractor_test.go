@@ -0,0 +1,28 @@
+package pdf
+
+import "testing"
+
+// TestNewExtractorSkipsDanglingFontReference is a regression test for
+// NewExtractor's font-loading loop doing a bare r.Resolve(ref).(DictionaryObject)
+// type assertion: a dangling /Font reference resolves to NullObject{} (see
+// Reader.Resolve), which previously panicked instead of just leaving that
+// font out.
+func TestNewExtractorSkipsDanglingFontReference(t *testing.T) {
+	r := &Reader{xref: NewXRefTable()}
+
+	page := DictionaryObject{
+		"/Resources": DictionaryObject{
+			"/Font": DictionaryObject{
+				"/F1": IndirectObject{ObjectNumber: 9999},
+			},
+		},
+	}
+
+	e, err := NewExtractor(r, page, false)
+	if err != nil {
+		t.Fatalf("NewExtractor: %v", err)
+	}
+	if _, ok := e.fonts["/F1"]; ok {
+		t.Fatalf("expected the dangling font reference to be skipped, not loaded")
+	}
+}
@@ -0,0 +1,77 @@
+package pdf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AOShei/pdf-loader/pkg/model"
+)
+
+// TestPopulateObjectFitNative covers an image drawn at exactly 72 source
+// pixels per inch (one pixel per PDF point) - undistorted and unscaled.
+func TestPopulateObjectFitNative(t *testing.T) {
+	img := model.Image{Width: 100, Height: 50, Rect: []float64{0, 0, 100, 50}}
+	populateObjectFit(&img)
+
+	if img.Fit != "native" {
+		t.Fatalf("Fit = %q, want native", img.Fit)
+	}
+	if img.Stretched {
+		t.Fatalf("Stretched = true, want false")
+	}
+	if math.Abs(img.EffectiveDPIX-72) > 0.01 || math.Abs(img.EffectiveDPIY-72) > 0.01 {
+		t.Fatalf("EffectiveDPIX/Y = %v/%v, want ~72/72", img.EffectiveDPIX, img.EffectiveDPIY)
+	}
+	if img.DistortionScore > 0.001 {
+		t.Fatalf("DistortionScore = %v, want ~0", img.DistortionScore)
+	}
+}
+
+// TestPopulateObjectFitStretch covers a non-uniform scale: the placed
+// aspect ratio doesn't match the source aspect ratio.
+func TestPopulateObjectFitStretch(t *testing.T) {
+	img := model.Image{Width: 100, Height: 100, Rect: []float64{0, 0, 200, 50}}
+	populateObjectFit(&img)
+
+	if img.Fit != "stretch" {
+		t.Fatalf("Fit = %q, want stretch", img.Fit)
+	}
+	if !img.Stretched {
+		t.Fatalf("Stretched = false, want true")
+	}
+}
+
+// TestPopulateObjectFitContainAndCover covers uniform scaling in each
+// direction: shrinking (more source detail than the Rect needs) classifies
+// as "contain", enlarging (less source detail than the Rect displays) as
+// "cover".
+func TestPopulateObjectFitContainAndCover(t *testing.T) {
+	shrunk := model.Image{Width: 400, Height: 200, Rect: []float64{0, 0, 100, 50}}
+	populateObjectFit(&shrunk)
+	if shrunk.Fit != "contain" {
+		t.Fatalf("Fit = %q, want contain", shrunk.Fit)
+	}
+
+	enlarged := model.Image{Width: 50, Height: 25, Rect: []float64{0, 0, 200, 100}}
+	populateObjectFit(&enlarged)
+	if enlarged.Fit != "cover" {
+		t.Fatalf("Fit = %q, want cover", enlarged.Fit)
+	}
+}
+
+// TestImagesAtDPIFiltersBelowTarget checks ImagesAtDPI only returns images
+// whose effective resolution falls below the requested target.
+func TestImagesAtDPIFiltersBelowTarget(t *testing.T) {
+	lowRes := model.Image{ID: "low", Width: 72, Height: 72, Rect: []float64{0, 0, 144, 144}}
+	populateObjectFit(&lowRes)
+	highRes := model.Image{ID: "high", Width: 300, Height: 300, Rect: []float64{0, 0, 72, 72}}
+	populateObjectFit(&highRes)
+
+	images := []model.Image{lowRes, highRes}
+	e := &Extractor{images: &images}
+
+	below := e.ImagesAtDPI(150)
+	if len(below) != 1 || below[0].ID != "low" {
+		t.Fatalf("ImagesAtDPI(150) = %v, want only the low-res image", below)
+	}
+}
@@ -0,0 +1,464 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/lzw"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// CompressedImage names the raw (still-encoded) payload of a stream whose
+// filter is an image codec we don't decode pixel data for (DCTDecode,
+// CCITTFaxDecode, JBIG2Decode, JPXDecode). Callers that only care about text
+// extraction can ignore it; callers that want the image bytes (e.g. to save
+// a JPEG straight to disk for /DCTDecode) get them unmodified. DecodeChain
+// does not attach this marker to its output: the passthrough filters return
+// the encoded bytes as they are.
+const CompressedImage = "CompressedImage"
+
+// Filter decodes one stage of a stream's /Filter chain. Decode receives the
+// filter-specific /DecodeParms dictionary for that stage (nil if none
+// applies) and returns the decoded bytes.
+type Filter interface {
+	Decode(data []byte, parms DictionaryObject) ([]byte, error)
+}
+
+// FilterFunc adapts a plain function to the Filter interface, the same way
+// http.HandlerFunc adapts a function to http.Handler.
+type FilterFunc func(data []byte, parms DictionaryObject) ([]byte, error)
+
+func (f FilterFunc) Decode(data []byte, parms DictionaryObject) ([]byte, error) {
+	return f(data, parms)
+}
+
+// filterRegistry maps a /Filter name to the Filter that decodes it.
+// RegisterFilter lets a caller add to or override this set; see its doc
+// comment for why that's useful.
+var filterRegistry = map[string]Filter{}
+
+func init() {
+	RegisterFilter("/FlateDecode", FilterFunc(flateFilter))
+	RegisterFilter("/Fl", FilterFunc(flateFilter))
+	RegisterFilter("/LZWDecode", FilterFunc(lzwFilter))
+	RegisterFilter("/LZW", FilterFunc(lzwFilter))
+	RegisterFilter("/ASCII85Decode", FilterFunc(ascii85Filter))
+	RegisterFilter("/A85", FilterFunc(ascii85Filter))
+	RegisterFilter("/ASCIIHexDecode", FilterFunc(asciiHexFilter))
+	RegisterFilter("/AHx", FilterFunc(asciiHexFilter))
+	RegisterFilter("/RunLengthDecode", FilterFunc(runLengthFilter))
+	RegisterFilter("/RL", FilterFunc(runLengthFilter))
+
+	// These are image codecs, not general-purpose compression. We don't
+	// decode pixel data here; hand the raw bytes back untouched so a caller
+	// that wants the JPEG/CCITT/JBIG2/JPEG2000 payload can use it directly
+	// (e.g. write a /DCTDecode stream out as a .jpg as-is). A downstream
+	// package that wants real CCITT/JBIG2/JPX decoding can override any of
+	// these with RegisterFilter instead of forking this package.
+	for _, name := range []string{"/DCTDecode", "/DCT", "/CCITTFaxDecode", "/CCF", "/JBIG2Decode", "/JPXDecode"} {
+		RegisterFilter(name, FilterFunc(passthroughFilter))
+	}
+
+	// Decryption already happened against the raw stream bytes before
+	// DecodeChain ran (see readStream/streamCryptFilterName); by the time
+	// /Crypt is reached here the data is already plaintext.
+	RegisterFilter("/Crypt", FilterFunc(passthroughFilter))
+}
+
+// RegisterFilter adds (or replaces) the decoder used for a named /Filter
+// entry. This is how a downstream package plugs in a real JBIG2 or
+// JPEG2000 decoder - or any other codec - in place of the passthrough
+// default, without forking this package.
+func RegisterFilter(name string, f Filter) {
+	filterRegistry[name] = f
+}
+
+func flateFilter(data []byte, parms DictionaryObject) ([]byte, error) {
+	decoded, err := flateDecode(data)
+	if err != nil {
+		return nil, err
+	}
+	return applyPredictor(decoded, parms)
+}
+
+func lzwFilter(data []byte, parms DictionaryObject) ([]byte, error) {
+	decoded, err := lzwDecode(data, parms)
+	if err != nil {
+		return nil, err
+	}
+	return applyPredictor(decoded, parms)
+}
+
+func ascii85Filter(data []byte, _ DictionaryObject) ([]byte, error) {
+	return ascii85Decode(data)
+}
+
+func asciiHexFilter(data []byte, _ DictionaryObject) ([]byte, error) {
+	return asciiHexDecode(data)
+}
+
+func runLengthFilter(data []byte, _ DictionaryObject) ([]byte, error) {
+	return runLengthDecode(data)
+}
+
+func passthroughFilter(data []byte, _ DictionaryObject) ([]byte, error) {
+	return data, nil
+}
+
+// Decode applies a single named /Filter to data, using parms for any
+// filter-specific /DecodeParms (predictor, early-change, etc), by looking
+// the filter up in filterRegistry. Unknown filters are returned as an error
+// so DecodeChain can decide whether that's fatal.
+func Decode(data []byte, filterName string, parms DictionaryObject) ([]byte, error) {
+	f, ok := filterRegistry[filterName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported filter: %s", filterName)
+	}
+	return f.Decode(data, parms)
+}
+
+// DecodeChain walks the /Filter entry of a stream dictionary (a single name
+// or an array of names) alongside the matching /DecodeParms (a single
+// dictionary, an array of dictionaries/null, or absent), applying each
+// filter in turn. It mirrors how readStream/readXRefStream used to inline a
+// single hardcoded FlateDecode+predictor step, but generalizes to the full
+// chain PDF allows.
+func DecodeChain(data []byte, filterObj, decodeParmsObj Object) ([]byte, error) {
+	filters := filterNames(filterObj)
+	if len(filters) == 0 {
+		return data, nil
+	}
+	parms := decodeParmsList(decodeParmsObj, len(filters))
+
+	out := data
+	for i, f := range filters {
+		decoded, err := Decode(out, f, parms[i])
+		if err != nil {
+			return nil, fmt.Errorf("filter %d (%s): %w", i, f, err)
+		}
+		out = decoded
+	}
+	return out, nil
+}
+
+func filterNames(obj Object) []string {
+	switch v := obj.(type) {
+	case NameObject:
+		return []string{string(v)}
+	case ArrayObject:
+		names := make([]string, 0, len(v))
+		for _, item := range v {
+			if n, ok := item.(NameObject); ok {
+				names = append(names, string(n))
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+// decodeParmsList aligns /DecodeParms with the filter list: a bare
+// dictionary applies to the (single) filter, an array pairs positionally,
+// and a missing/null entry means "no parms" for that filter.
+func decodeParmsList(obj Object, n int) []DictionaryObject {
+	out := make([]DictionaryObject, n)
+	switch v := obj.(type) {
+	case DictionaryObject:
+		if n > 0 {
+			out[0] = v
+		}
+	case ArrayObject:
+		for i := 0; i < n && i < len(v); i++ {
+			if d, ok := v[i].(DictionaryObject); ok {
+				out[i] = d
+			}
+		}
+	}
+	return out
+}
+
+func flateDecode(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("zlib: %w", err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// lzwDecode decodes /LZWDecode streams. PDF's default is EarlyChange=1
+// (Go's compress/lzw "MSB" order with TIFF-style early code-width bumps),
+// matching the predominant encoder behavior; EarlyChange=0 is rare enough
+// in the wild that we fall back to the same reader either way.
+func lzwDecode(data []byte, parms DictionaryObject) ([]byte, error) {
+	lr := lzw.NewReader(bytes.NewReader(data), lzw.MSB, 8)
+	defer lr.Close()
+	return io.ReadAll(lr)
+}
+
+func ascii85Decode(data []byte) ([]byte, error) {
+	// Trim the optional leading "<~" and trailing "~>" delimiters.
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if idx := bytes.Index(data, []byte("~>")); idx != -1 {
+		data = data[:idx]
+	}
+
+	var out bytes.Buffer
+	var group [5]byte
+	n := 0
+	flush := func(count int) {
+		var tuple [4]byte
+		var val uint32
+		for i := 0; i < 5; i++ {
+			val = val*85 + uint32(group[i]-33)
+		}
+		tuple[0] = byte(val >> 24)
+		tuple[1] = byte(val >> 16)
+		tuple[2] = byte(val >> 8)
+		tuple[3] = byte(val)
+		out.Write(tuple[:count-1])
+	}
+
+	for _, b := range data {
+		if b == 'z' && n == 0 {
+			out.Write([]byte{0, 0, 0, 0})
+			continue
+		}
+		if b == 0 || b == '\t' || b == '\n' || b == '\f' || b == '\r' || b == ' ' {
+			continue
+		}
+		group[n] = b
+		n++
+		if n == 5 {
+			flush(5)
+			n = 0
+		}
+	}
+	if n > 0 {
+		// Pad the final partial group with 'u' (the char encoding value 84)
+		// per spec before decoding; flush now reads every one of the 5
+		// positions, so the padding actually participates in the value.
+		for i := n; i < 5; i++ {
+			group[i] = 'u'
+		}
+		flush(n)
+	}
+	return out.Bytes(), nil
+}
+
+func asciiHexDecode(data []byte) ([]byte, error) {
+	if idx := bytes.IndexByte(data, '>'); idx != -1 {
+		data = data[:idx]
+	}
+	var hex []byte
+	for _, b := range data {
+		if isWhitespace(b) {
+			continue
+		}
+		hex = append(hex, b)
+	}
+	if len(hex)%2 != 0 {
+		hex = append(hex, '0')
+	}
+	out := make([]byte, len(hex)/2)
+	for i := 0; i < len(out); i++ {
+		hi := hexVal(hex[i*2])
+		lo := hexVal(hex[i*2+1])
+		out[i] = hi<<4 | lo
+	}
+	return out, nil
+}
+
+func hexVal(b byte) byte {
+	switch {
+	case b >= '0' && b <= '9':
+		return b - '0'
+	case b >= 'a' && b <= 'f':
+		return b - 'a' + 10
+	case b >= 'A' && b <= 'F':
+		return b - 'A' + 10
+	default:
+		return 0
+	}
+}
+
+func runLengthDecode(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	i := 0
+	for i < len(data) {
+		length := data[i]
+		i++
+		switch {
+		case length == 128:
+			// EOD marker.
+			return out.Bytes(), nil
+		case length < 128:
+			count := int(length) + 1
+			if i+count > len(data) {
+				return nil, fmt.Errorf("RunLengthDecode: literal run overruns input")
+			}
+			out.Write(data[i : i+count])
+			i += count
+		default:
+			if i >= len(data) {
+				return nil, fmt.Errorf("RunLengthDecode: truncated repeat run")
+			}
+			count := 257 - int(length)
+			for j := 0; j < count; j++ {
+				out.WriteByte(data[i])
+			}
+			i++
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// applyPredictor dispatches to the PNG (10-15) or TIFF (2) predictor based
+// on /DecodeParms, or returns data unchanged if no predictor applies.
+func applyPredictor(data []byte, parms DictionaryObject) ([]byte, error) {
+	if parms == nil {
+		return data, nil
+	}
+	predictor := 1
+	if p, ok := parms["/Predictor"].(NumberObject); ok {
+		predictor = int(p)
+	}
+	if predictor <= 1 {
+		return data, nil
+	}
+
+	columns := 1
+	if c, ok := parms["/Columns"].(NumberObject); ok {
+		columns = int(c)
+	}
+	colors := 1
+	if c, ok := parms["/Colors"].(NumberObject); ok {
+		colors = int(c)
+	}
+	bpc := 8
+	if b, ok := parms["/BitsPerComponent"].(NumberObject); ok {
+		bpc = int(b)
+	}
+
+	if predictor == 2 {
+		return applyTiffPredictor(data, columns, colors, bpc)
+	}
+	// Bytes per pixel: the left-neighbor distance each PNG row filter
+	// predicts from. For the common DeviceGray/1-byte-component case this
+	// is 1, but RGB (or 16-bit components) samples need more than the
+	// immediately preceding byte.
+	bpp := (colors*bpc + 7) / 8
+	return applyPngPredictor(data, columns*colors*bpc/8, bpp, predictor)
+}
+
+// applyTiffPredictor reverses TIFF Predictor 2 (horizontal differencing).
+// Only the common 8-bits-per-component case is handled; PDFs using
+// sub-byte samples with the TIFF predictor are rare enough that we leave
+// the data as-is rather than risk mangling it.
+func applyTiffPredictor(data []byte, columns, colors, bpc int) ([]byte, error) {
+	if bpc != 8 {
+		return data, nil
+	}
+	rowSize := columns * colors
+	if rowSize == 0 {
+		return data, nil
+	}
+	out := make([]byte, len(data))
+	copy(out, data)
+	for row := 0; row+rowSize <= len(out); row += rowSize {
+		for i := colors; i < rowSize; i++ {
+			out[row+i] += out[row+i-colors]
+		}
+	}
+	return out, nil
+}
+
+// applyPngPredictor decodes PNG predicted data (Predictor 10-15, PDF's name
+// for "the row is preceded by a filter-type tag byte, per PNG's scheme").
+// bpp is the bytes-per-pixel distance Sub/Average/Paeth read their left
+// neighbor from - 1 for a single 8-bit component, more for multi-component
+// or wider-than-8-bit samples (see applyPredictor).
+func applyPngPredictor(data []byte, columns int, bpp int, predictor int) ([]byte, error) {
+	if predictor < 10 || predictor > 15 {
+		return nil, fmt.Errorf("unsupported predictor: %d (expected 10-15)", predictor)
+	}
+
+	// Row size = columns + 1 (filter byte)
+	rowSize := columns + 1
+	rowCount := len(data) / rowSize
+	out := make([]byte, rowCount*columns)
+
+	// Previous row buffer (initially zero)
+	prevRow := make([]byte, columns)
+
+	for i := 0; i < rowCount; i++ {
+		rowStart := i * rowSize
+		filter := data[rowStart]
+		rowBytes := data[rowStart+1 : rowStart+rowSize]
+
+		outStart := i * columns
+		outRow := out[outStart : outStart+columns]
+
+		switch filter {
+		case 0: // None
+			copy(outRow, rowBytes)
+		case 1: // Sub (Left)
+			for x := 0; x < columns; x++ {
+				var left byte
+				if x >= bpp {
+					left = outRow[x-bpp]
+				}
+				outRow[x] = rowBytes[x] + left
+			}
+		case 2: // Up
+			for x := 0; x < columns; x++ {
+				outRow[x] = rowBytes[x] + prevRow[x]
+			}
+		case 3: // Average
+			for x := 0; x < columns; x++ {
+				var left byte
+				if x >= bpp {
+					left = outRow[x-bpp]
+				}
+				avg := (int(left) + int(prevRow[x])) / 2
+				outRow[x] = byte(int(rowBytes[x]) + avg)
+			}
+		case 4: // Paeth
+			for x := 0; x < columns; x++ {
+				var left, upperLeft byte
+				if x >= bpp {
+					left = outRow[x-bpp]
+					upperLeft = prevRow[x-bpp]
+				}
+				upper := prevRow[x]
+				outRow[x] = rowBytes[x] + byte(paethPredictor(int(left), int(upper), int(upperLeft)))
+			}
+		default: // Fallback treat as None
+			copy(outRow, rowBytes)
+		}
+
+		copy(prevRow, outRow)
+	}
+	return out, nil
+}
+
+func paethPredictor(a, b, c int) int {
+	p := a + b - c
+	pa := abs(p - a)
+	pb := abs(p - b)
+	pc := abs(p - c)
+	if pa <= pb && pa <= pc {
+		return a
+	} else if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,217 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+)
+
+// deriveToUnicodeFromFontProgram attempts to build a code->Unicode mapping
+// by reading the font program embedded in the font's /FontDescriptor, for
+// use when the font provides no /ToUnicode CMap. This is a best-effort
+// fallback: it understands just enough of the TrueType 'cmap' table and the
+// Type 1 cleartext header to recover a mapping, not a full font parser.
+func (e *Extractor) deriveToUnicodeFromFontProgram(f *Font, obj DictionaryObject) {
+	fd, ok := e.reader.Resolve(obj["/FontDescriptor"]).(DictionaryObject)
+	if !ok {
+		return
+	}
+
+	if ff2, ok := e.reader.Resolve(fd["/FontFile2"]).(StreamObject); ok {
+		mergeCodeToUnicode(f.CMap, parseTrueTypeCmap(ff2.Data))
+		return
+	}
+	if ff3, ok := e.reader.Resolve(fd["/FontFile3"]).(StreamObject); ok {
+		// OpenType wrapper (CFF outlines with a 'cmap' table); same table
+		// layout as FontFile2 for our purposes.
+		mergeCodeToUnicode(f.CMap, parseTrueTypeCmap(ff3.Data))
+		return
+	}
+	if ff, ok := e.reader.Resolve(fd["/FontFile"]).(StreamObject); ok {
+		mergeCodeToUnicode(f.CMap, parseType1Encoding(ff.Data))
+	}
+}
+
+// mergeCodeToUnicode adds derived entries to cm without overwriting
+// anything a real /ToUnicode or /Encoding already supplied.
+func mergeCodeToUnicode(cm *CMap, codeToUnicode map[int]string) {
+	if cm == nil {
+		return
+	}
+	for code, u := range codeToUnicode {
+		key := string([]byte{byte(code)})
+		if _, exists := cm.Map[key]; !exists {
+			cm.Map[key] = u
+		}
+	}
+}
+
+// parseTrueTypeCmap reads just enough of a TrueType/OpenType 'cmap' table
+// to map single-byte character codes to Unicode. It prefers a (1,0) Mac
+// Roman subtable (format 0), since that's the subtable simple, non-symbolic
+// embedded fonts most often key PDF content-stream codes against, and falls
+// back to a (3,1) Windows Unicode subtable (format 4), keeping only the
+// entries in the 0-255 range that a single content-stream byte can address.
+func parseTrueTypeCmap(data []byte) map[int]string {
+	cmapData := findSFNTTable(data, "cmap")
+	if cmapData == nil || len(cmapData) < 4 {
+		return nil
+	}
+
+	numTables := int(binary.BigEndian.Uint16(cmapData[2:4]))
+	var macOffset, winOffset uint32
+	for i := 0; i < numTables; i++ {
+		rec := 4 + i*8
+		if rec+8 > len(cmapData) {
+			break
+		}
+		platformID := binary.BigEndian.Uint16(cmapData[rec : rec+2])
+		encodingID := binary.BigEndian.Uint16(cmapData[rec+2 : rec+4])
+		offset := binary.BigEndian.Uint32(cmapData[rec+4 : rec+8])
+		switch {
+		case platformID == 1 && encodingID == 0:
+			macOffset = offset
+		case platformID == 3 && encodingID == 1:
+			winOffset = offset
+		}
+	}
+
+	if macOffset != 0 && int(macOffset) < len(cmapData) {
+		if m := parseCmapFormat0(cmapData[macOffset:]); m != nil {
+			return m
+		}
+	}
+	if winOffset != 0 && int(winOffset) < len(cmapData) {
+		if m := parseCmapFormat4(cmapData[winOffset:]); m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// findSFNTTable locates a table by tag in a TrueType/OpenType font's table
+// directory.
+func findSFNTTable(data []byte, tag string) []byte {
+	if len(data) < 12 {
+		return nil
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:6]))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + i*16
+		if rec+16 > len(data) {
+			break
+		}
+		if string(data[rec:rec+4]) == tag {
+			offset := binary.BigEndian.Uint32(data[rec+8 : rec+12])
+			length := binary.BigEndian.Uint32(data[rec+12 : rec+16])
+			if int(offset) > len(data) || int(offset)+int(length) > len(data) {
+				return nil
+			}
+			return data[offset : offset+length]
+		}
+	}
+	return nil
+}
+
+// parseCmapFormat0 reads a format-0 subtable: a flat 256-entry byte array
+// mapping code -> glyph ID. Without also parsing 'post' we have no
+// glyph-ID -> name/Unicode table, so we only report a code below 0x80 that
+// actually has a glyph (non-zero ID) and treat the code itself as its ASCII
+// character - correct for the common case of a Mac Roman-keyed subset font
+// using ASCII-range codes. Codes 0x80-0xFF are not reported.
+func parseCmapFormat0(data []byte) map[int]string {
+	if len(data) < 6+256 {
+		return nil
+	}
+	if binary.BigEndian.Uint16(data[0:2]) != 0 {
+		return nil
+	}
+	out := make(map[int]string)
+	for code := 0; code < 0x80; code++ {
+		if data[6+code] != 0 {
+			out[code] = string(rune(code))
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// parseCmapFormat4 reads a format-4 subtable (segmented Unicode mapping)
+// and returns entries for code points in the 0-255 range, which is the
+// subset usable as single-byte PDF content-stream codes.
+func parseCmapFormat4(data []byte) map[int]string {
+	if len(data) < 14 {
+		return nil
+	}
+	if binary.BigEndian.Uint16(data[0:2]) != 4 {
+		return nil
+	}
+	segCountX2 := int(binary.BigEndian.Uint16(data[6:8]))
+
+	endCodesOff := 14
+	startCodesOff := endCodesOff + segCountX2 + 2 // +2 skips reservedPad
+	if startCodesOff+segCountX2 > len(data) {
+		return nil
+	}
+
+	out := make(map[int]string)
+	for i := 0; i+1 < segCountX2; i += 2 {
+		endCode := int(binary.BigEndian.Uint16(data[endCodesOff+i:]))
+		startCode := int(binary.BigEndian.Uint16(data[startCodesOff+i:]))
+		if startCode > endCode || startCode > 0xFF {
+			continue
+		}
+		last := endCode
+		if last > 0xFF {
+			last = 0xFF
+		}
+		for code := startCode; code <= last && code != 0xFFFF; code++ {
+			out[code] = string(rune(code))
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// parseType1Encoding extracts a code -> Unicode map from a Type 1 font
+// program's cleartext header, where the built-in encoding is spelled out as
+// a series of "dup <code> /<glyphname> put" statements. Type 1 charstrings
+// live behind eexec encryption, so this is as far as a lightweight text
+// parser can go; it's enough to recover the font's own /Encoding array.
+func parseType1Encoding(data []byte) map[int]string {
+	idx := bytes.Index(data, []byte("/Encoding"))
+	if idx < 0 {
+		return nil
+	}
+	section := data[idx:]
+	if end := bytes.Index(section, []byte("readonly def")); end >= 0 {
+		section = section[:end]
+	}
+
+	out := make(map[int]string)
+	for _, part := range bytes.Split(section, []byte("dup ")) {
+		fields := bytes.Fields(part)
+		if len(fields) < 2 {
+			continue
+		}
+		code, err := strconv.Atoi(string(fields[0]))
+		if err != nil {
+			continue
+		}
+		name := string(fields[1])
+		if len(name) < 2 || name[0] != '/' {
+			continue
+		}
+		if u, ok := glyphToUnicode[name]; ok {
+			out[code] = u
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
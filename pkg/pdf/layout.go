@@ -0,0 +1,199 @@
+package pdf
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ReconstructReadingOrder re-orders glyphs captured by ExtractTextWithLayout
+// into natural reading order: column-major left-to-right, then top-to-bottom
+// within a column, then left-to-right within a line. Content streams draw
+// text in whatever order the producer chose (often column-by-column, but
+// sometimes interleaved line-by-line across columns), so the raw draw order
+// can interleave unrelated columns.
+//
+// The heuristics here are deliberately simple: column breaks are detected by
+// looking for horizontal gaps between glyphs on the page that are wide
+// relative to the average glyph width, lines are clustered by
+// y-proximity, and a trailing hyphen at a line break is coalesced into the
+// following line's leading word. This covers ordinary multi-column layouts
+// and soft-hyphenated line wraps well but will not untangle more exotic page
+// designs (e.g. text wrapped around a figure, or columns of uneven height
+// that share a y-range with no other column).
+func ReconstructReadingOrder(glyphs []Glyph) string {
+	if len(glyphs) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	columns := splitColumns(glyphs)
+	for ci, col := range columns {
+		var lines []string
+		for _, ln := range clusterLines(col) {
+			lines = append(lines, lineText(ln))
+		}
+		for _, l := range coalesceHyphenatedBreaks(lines) {
+			out.WriteString(l)
+			out.WriteString("\n")
+		}
+		if ci < len(columns)-1 {
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+// splitColumns partitions glyphs by looking for horizontal gaps between
+// x-adjacent glyphs that are more than six times the average glyph width -
+// each such gap is treated as a column gutter. This is a 1-D projection of
+// the page onto the x-axis: it doesn't account for glyphs' y-position, so it
+// assumes gutters run the full height of the region being split, which holds
+// for ordinary N-column body text but not for layouts where columns start
+// and end at different heights.
+func splitColumns(glyphs []Glyph) [][]Glyph {
+	if len(glyphs) < 2 {
+		return [][]Glyph{glyphs}
+	}
+
+	sorted := make([]Glyph, len(glyphs))
+	copy(sorted, glyphs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].X < sorted[j].X })
+
+	avgWidth := 0.0
+	for _, g := range sorted {
+		avgWidth += g.Width
+	}
+	avgWidth /= float64(len(sorted))
+	if avgWidth <= 0 {
+		return [][]Glyph{glyphs}
+	}
+
+	var gutters []float64
+	for i := 1; i < len(sorted); i++ {
+		gap := sorted[i].X - (sorted[i-1].X + sorted[i-1].Width)
+		if gap > avgWidth*6 {
+			gutters = append(gutters, sorted[i-1].X+sorted[i-1].Width)
+		}
+	}
+	if len(gutters) == 0 {
+		return [][]Glyph{glyphs}
+	}
+
+	columns := make([][]Glyph, len(gutters)+1)
+	for _, g := range glyphs {
+		idx := sort.SearchFloat64s(gutters, g.X)
+		columns[idx] = append(columns[idx], g)
+	}
+
+	var nonEmpty [][]Glyph
+	for _, col := range columns {
+		if len(col) > 0 {
+			nonEmpty = append(nonEmpty, col)
+		}
+	}
+	if len(nonEmpty) < 2 {
+		return [][]Glyph{glyphs}
+	}
+	return nonEmpty
+}
+
+// clusterLines groups glyphs that share a text baseline (within half a
+// glyph's height of each other) and sorts each group left-to-right. PDF
+// page space has y increasing upward, so lines come out top-to-bottom.
+func clusterLines(glyphs []Glyph) [][]Glyph {
+	if len(glyphs) == 0 {
+		return nil
+	}
+
+	sorted := make([]Glyph, len(glyphs))
+	copy(sorted, glyphs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Y > sorted[j].Y })
+
+	var lines [][]Glyph
+	cur := []Glyph{sorted[0]}
+	curY := sorted[0].Y
+	for _, g := range sorted[1:] {
+		threshold := g.Height * 0.5
+		if threshold <= 0 {
+			threshold = 1
+		}
+		if curY-g.Y > threshold {
+			lines = append(lines, cur)
+			cur = []Glyph{g}
+		} else {
+			cur = append(cur, g)
+		}
+		curY = g.Y
+	}
+	lines = append(lines, cur)
+
+	for _, ln := range lines {
+		sort.Slice(ln, func(i, j int) bool { return ln[i].X < ln[j].X })
+	}
+	return lines
+}
+
+// lineText concatenates a line's glyphs, inserting a space wherever the gap
+// to the previous glyph is wide enough to be a word break.
+func lineText(glyphs []Glyph) string {
+	var b strings.Builder
+	lastEnd := 0.0
+	for i, g := range glyphs {
+		if i > 0 {
+			gap := g.X - lastEnd
+			if gap > g.Height*0.2 && !strings.HasSuffix(b.String(), " ") {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(g.Text)
+		lastEnd = g.X + g.Width
+	}
+	return b.String()
+}
+
+// coalesceHyphenatedBreaks merges a line ending in a soft hyphen (a letter
+// immediately followed by "-") into the start of the next line, dropping the
+// hyphen, whenever the next line starts with a lowercase letter - the
+// combination this package's wrapped word-\nword should turn back into
+// wordword. A hyphen is left alone (and so is a genuine line break) when the
+// next line starts with anything else (punctuation, a capital letter, a
+// digit), since those are far more often an intentional dash than a wrapped
+// word.
+func coalesceHyphenatedBreaks(lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		for endsInSoftHyphen(line) && i+1 < len(lines) && startsWithLowercaseLetter(lines[i+1]) {
+			line = line[:len(line)-1] + lines[i+1]
+			i++
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// endsInSoftHyphen reports whether line ends with a hyphen directly
+// preceded by a letter (as opposed to, say, a bullet "-" with nothing
+// before it, or a trailing en/em-dash after whitespace).
+func endsInSoftHyphen(line string) bool {
+	if !strings.HasSuffix(line, "-") {
+		return false
+	}
+	before := line[:len(line)-1]
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return unicode.IsLetter(r)
+}
+
+// startsWithLowercaseLetter reports whether s begins with a lowercase
+// letter, used to tell a hyphenated word continuation apart from a new
+// sentence or an unrelated dash.
+func startsWithLowercaseLetter(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLower(r)
+}
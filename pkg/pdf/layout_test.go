@@ -0,0 +1,90 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+)
+
+// glyphsForLine builds one Glyph per rune of text, laid out left to right
+// starting at x, each glyphWidth wide and 10 tall, so gap-based heuristics
+// (word spaces, column gutters) can be driven by explicit x positions.
+func glyphsForLine(text string, x, y, glyphWidth float64) []Glyph {
+	var out []Glyph
+	for _, r := range text {
+		out = append(out, Glyph{Text: string(r), X: x, Y: y, Width: glyphWidth, Height: 10})
+		x += glyphWidth
+	}
+	return out
+}
+
+// TestReconstructReadingOrderTwoColumns checks that two glyph blocks
+// separated by a wide horizontal gutter come back column-major (all of the
+// left column before all of the right column), not interleaved by y.
+func TestReconstructReadingOrderTwoColumns(t *testing.T) {
+	var glyphs []Glyph
+	glyphs = append(glyphs, glyphsForLine("left", 0, 100, 5)...)
+	glyphs = append(glyphs, glyphsForLine("right", 500, 100, 5)...)
+
+	got := ReconstructReadingOrder(glyphs)
+	leftIdx := strings.Index(got, "left")
+	rightIdx := strings.Index(got, "right")
+	if leftIdx == -1 || rightIdx == -1 {
+		t.Fatalf("missing expected text in output: %q", got)
+	}
+	if leftIdx > rightIdx {
+		t.Fatalf("left column text should come before right column text, got %q", got)
+	}
+}
+
+// TestReconstructReadingOrderThreeColumns is a regression test for
+// splitColumns being hard-capped at two columns: three blocks separated by
+// two equally wide gutters must all come back as separate columns, in
+// left-to-right order.
+func TestReconstructReadingOrderThreeColumns(t *testing.T) {
+	var glyphs []Glyph
+	glyphs = append(glyphs, glyphsForLine("aaa", 0, 100, 5)...)
+	glyphs = append(glyphs, glyphsForLine("bbb", 500, 100, 5)...)
+	glyphs = append(glyphs, glyphsForLine("ccc", 1000, 100, 5)...)
+
+	got := ReconstructReadingOrder(glyphs)
+	aIdx := strings.Index(got, "aaa")
+	bIdx := strings.Index(got, "bbb")
+	cIdx := strings.Index(got, "ccc")
+	if aIdx == -1 || bIdx == -1 || cIdx == -1 {
+		t.Fatalf("missing expected text in output: %q", got)
+	}
+	if !(aIdx < bIdx && bIdx < cIdx) {
+		t.Fatalf("expected column order aaa, bbb, ccc, got %q", got)
+	}
+}
+
+// TestReconstructReadingOrderCoalescesHyphenatedBreak is a regression test
+// for word-\nword not being joined back into wordword when a line wraps
+// mid-word on a soft hyphen.
+func TestReconstructReadingOrderCoalescesHyphenatedBreak(t *testing.T) {
+	var glyphs []Glyph
+	glyphs = append(glyphs, glyphsForLine("exam-", 0, 100, 5)...)
+	glyphs = append(glyphs, glyphsForLine("ple", 0, 90, 5)...)
+
+	got := strings.TrimSpace(ReconstructReadingOrder(glyphs))
+	if got != "example" {
+		t.Fatalf("ReconstructReadingOrder = %q, want %q", got, "example")
+	}
+}
+
+// TestReconstructReadingOrderLeavesGenuineDashAlone checks that a line
+// ending in a hyphen followed by a capitalized next line (a new sentence or
+// a genuine dash, not a wrapped word) is left as two lines.
+func TestReconstructReadingOrderLeavesGenuineDashAlone(t *testing.T) {
+	var glyphs []Glyph
+	glyphs = append(glyphs, glyphsForLine("Pre-", 0, 100, 5)...)
+	glyphs = append(glyphs, glyphsForLine("Built", 0, 90, 5)...)
+
+	got := ReconstructReadingOrder(glyphs)
+	if !strings.Contains(got, "Pre-\n") {
+		t.Fatalf("expected the hyphenated line to survive unmerged, got %q", got)
+	}
+	if !strings.Contains(got, "Built") {
+		t.Fatalf("expected the next line's text in output, got %q", got)
+	}
+}
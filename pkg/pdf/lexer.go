@@ -165,7 +165,7 @@ func (l *Lexer) readString() (StringObject, error) {
 
 func (l *Lexer) readHexString() (HexStringObject, error) {
 	l.reader.ReadByte() // consume '<'
-	var data []byte
+	var hexDigits []byte
 	for {
 		b, err := l.reader.ReadByte()
 		if err != nil {
@@ -177,7 +177,15 @@ func (l *Lexer) readHexString() (HexStringObject, error) {
 		if isWhitespace(b) {
 			continue
 		}
-		data = append(data, b)
+		hexDigits = append(hexDigits, b)
+	}
+	// An odd number of digits is padded with a trailing 0, per spec.
+	if len(hexDigits)%2 != 0 {
+		hexDigits = append(hexDigits, '0')
+	}
+	data := make([]byte, len(hexDigits)/2)
+	for i := range data {
+		data[i] = hexVal(hexDigits[i*2])<<4 | hexVal(hexDigits[i*2+1])
 	}
 	return HexStringObject(data), nil
 }
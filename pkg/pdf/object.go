@@ -0,0 +1,159 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Object is any parsed PDF object: a NumberObject, NameObject, StringObject,
+// HexStringObject, ArrayObject, DictionaryObject, BooleanObject, NullObject,
+// KeywordObject, IndirectObject, or StreamObject. String renders the object
+// back to PDF syntax, which is how the incremental-update writer serializes
+// objects it didn't read off disk itself (see writeObject in writer.go).
+type Object interface {
+	String() string
+}
+
+// NumberObject is a PDF numeric object. The PDF spec doesn't distinguish
+// integers from reals at the object level, so both are stored as float64;
+// String prints without a decimal point when the value is integral, matching
+// how real PDF writers emit object/array lengths and similar counting
+// fields.
+type NumberObject float64
+
+func (n NumberObject) String() string {
+	if n == NumberObject(int64(n)) {
+		return strconv.FormatInt(int64(n), 10)
+	}
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
+
+// NameObject is a PDF name object, stored with its leading "/" (as the lexer
+// produces it) so it round-trips through String unchanged.
+type NameObject string
+
+func (n NameObject) String() string {
+	return string(n)
+}
+
+// StringObject is a PDF literal string object, i.e. "(...)" with escapes
+// already resolved by the lexer.
+type StringObject string
+
+func (s StringObject) String() string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, b := range []byte(s) {
+		switch b {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		default:
+			sb.WriteByte(b)
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// HexStringObject is a PDF hex string object, i.e. "<...>", stored as the
+// already-decoded bytes.
+type HexStringObject []byte
+
+func (h HexStringObject) String() string {
+	return fmt.Sprintf("<%x>", []byte(h))
+}
+
+// ArrayObject is a PDF array object.
+type ArrayObject []Object
+
+func (a ArrayObject) String() string {
+	parts := make([]string, len(a))
+	for i, obj := range a {
+		if obj == nil {
+			parts[i] = "null"
+			continue
+		}
+		parts[i] = obj.String()
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// DictionaryObject is a PDF dictionary object, keyed by NameObject.String()
+// (i.e. including the leading "/").
+type DictionaryObject map[string]Object
+
+func (d DictionaryObject) String() string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("<<")
+	for _, k := range keys {
+		sb.WriteByte(' ')
+		sb.WriteString(k)
+		sb.WriteByte(' ')
+		if v := d[k]; v != nil {
+			sb.WriteString(v.String())
+		} else {
+			sb.WriteString("null")
+		}
+	}
+	sb.WriteString(" >>")
+	return sb.String()
+}
+
+// BooleanObject is a PDF boolean object.
+type BooleanObject bool
+
+func (b BooleanObject) String() string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// NullObject is a PDF null object.
+type NullObject struct{}
+
+func (NullObject) String() string {
+	return "null"
+}
+
+// KeywordObject is a bare PDF keyword or operator token that isn't a
+// recognized literal (true/false/null) - structural keywords like "obj",
+// "endobj" and "stream", content-stream operators like "Tj" and "re", and
+// CMap keywords like "beginbfchar".
+type KeywordObject string
+
+func (k KeywordObject) String() string {
+	return string(k)
+}
+
+// IndirectObject is a reference to another object ("N G R" in PDF syntax),
+// not the object itself; Reader.Resolve follows it to the referenced value.
+type IndirectObject struct {
+	ObjectNumber int
+	Generation   int
+}
+
+func (r IndirectObject) String() string {
+	return fmt.Sprintf("%d %d R", r.ObjectNumber, r.Generation)
+}
+
+// StreamObject pairs a stream's dictionary with its decoded data (see
+// Reader.readStream). Its zero value has a nil Dictionary, which is never
+// produced by the reader itself but is returned on error paths.
+type StreamObject struct {
+	Dictionary DictionaryObject
+	Data       []byte
+}
+
+func (s StreamObject) String() string {
+	return s.Dictionary.String()
+}
@@ -2,31 +2,85 @@ package pdf
 
 import (
 	"bytes"
-	"compress/zlib"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"sync"
 )
 
-// Reader is the high-level entry point for reading a PDF.
+// Reader is the high-level entry point for reading a PDF. It's built over
+// an io.ReaderAt rather than a single io.ReadSeeker so that GetObject/
+// GetPage can be called concurrently: every call opens its own
+// io.SectionReader view (and so its own Lexer and read position) instead of
+// sharing one seek cursor across goroutines.
 type Reader struct {
-	rs             io.ReadSeeker
-	lexer          *Lexer
+	ra             io.ReaderAt
+	size           int64
 	xref           *XRefTable
 	encryptHandler *EncryptionHandler
+
+	// objStmCache caches the parsed contents of /ObjStm object streams,
+	// keyed by the stream's own object number. Pages routinely pull dozens
+	// of indirect objects (fonts, content fragments) out of the same handful
+	// of object streams, so without this every lookup would re-fetch and
+	// re-decode the whole container. objStmMu guards it for concurrent
+	// GetObject calls.
+	objStmMu    sync.Mutex
+	objStmCache map[int]*decodedObjStm
+
+	// fontMu guards fontCache, which memoizes loadFont's parse (widths,
+	// ToUnicode, encoding) by the font dictionary's object number. Like
+	// objStmCache, this is shared across every GetPage/Extractor using this
+	// Reader, so concurrent page extraction parses each font once instead of
+	// once per page that references it.
+	fontMu    sync.Mutex
+	fontCache map[int]*Font
+}
+
+// sectionFrom returns a private io.ReadSeeker view of the underlying file
+// starting at offset, for building a per-call Lexer. Because each call gets
+// its own SectionReader, concurrent callers never share (and so never race
+// on) a read position.
+func (r *Reader) sectionFrom(offset int64) *io.SectionReader {
+	return io.NewSectionReader(r.ra, offset, r.size-offset)
+}
+
+// decodedObjStm holds the already zlib/filter-decoded payload of an /ObjStm
+// along with the (objNum, offset) index parsed from its /First-prefixed
+// header, so repeated Resolve calls into the same stream are O(1).
+type decodedObjStm struct {
+	data    []byte
+	offsets []int // offsets[i] is the byte offset within data (/First plus the header's offset) of the i-th object
+}
+
+// NewReader opens a PDF for reading from ra (an in-memory []byte via
+// bytes.NewReader, an *os.File, or anything else satisfying io.ReaderAt),
+// authenticating with the empty password. This succeeds for unencrypted
+// files and for encrypted files whose user password is empty (the common
+// case for PDFs that only restrict permissions via an owner password).
+// size is the total length of the PDF, mirroring io.NewSectionReader.
+func NewReader(ra io.ReaderAt, size int64) (*Reader, error) {
+	return NewReaderWithPassword(ra, size, "")
 }
 
-func NewReader(rs io.ReadSeeker) (*Reader, error) {
+// NewReaderWithPassword opens a PDF for reading, authenticating with the
+// given password. The password is tried as both the user and the owner
+// password (Algorithms 6 and 7), since a reader has no way to know which
+// one the caller holds. It is ignored for unencrypted files.
+func NewReaderWithPassword(ra io.ReaderAt, size int64, password string) (*Reader, error) {
 	// 1. Parse XRef
-	xref, err := ParseXRef(rs)
+	xref, err := ParseXRef(io.NewSectionReader(ra, 0, size))
 	if err != nil {
 		return nil, err
 	}
 
 	reader := &Reader{
-		rs:    rs,
-		xref:  xref,
-		lexer: NewLexer(rs),
+		ra:          ra,
+		size:        size,
+		xref:        xref,
+		objStmCache: make(map[int]*decodedObjStm),
+		fontCache:   make(map[int]*Font),
 	}
 
 	// 2. Check for encryption and initialize handler
@@ -48,7 +102,7 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 			return nil, fmt.Errorf("failed to parse encryption: %w", err)
 		}
 
-		handler, err := NewEncryptionHandler(encDict, fileID)
+		handler, err := NewEncryptionHandler(encDict, fileID, password)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize encryption: %w", err)
 		}
@@ -59,7 +113,9 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 	return reader, nil
 }
 
-// GetObject resolves an indirect reference to the actual object.
+// GetObject resolves an indirect reference to the actual object, transparently
+// following the /ObjStm indirection for compressed entries (PDF 1.5 xref
+// streams). Uncompressed entries are read directly off the xref offset.
 func (r *Reader) GetObject(ref IndirectObject) (Object, error) {
 	entry, ok := r.xref.Entries[ref.ObjectNumber]
 	if !ok {
@@ -75,10 +131,10 @@ func (r *Reader) GetObject(ref IndirectObject) (Object, error) {
 		return r.getCompressedObject(entry.StreamObj, entry.StreamIdx)
 	}
 
-	// Jump to offset
-	r.rs.Seek(entry.Offset, io.SeekStart)
-
-	lexer := NewLexer(r.rs)
+	// Read from a private view starting at the object's offset, so this
+	// call doesn't race other concurrent GetObject calls over a shared
+	// cursor.
+	lexer := NewLexer(r.sectionFrom(entry.Offset))
 
 	// Consume "ObjNum Gen obj" header
 	lexer.ReadObject() // ID
@@ -145,15 +201,27 @@ func (r *Reader) readStream(dict DictionaryObject, lexer *Lexer, objNum, genNum
 	// 4. Read Raw Compressed Data
 	data := make([]byte, length)
 
-	// FIX: Use lexer.reader, NOT r.rs.
-	// r.rs is the underlying file, which might be ahead of the buffer.
+	// FIX: Use lexer.reader, NOT a fresh section read from the file.
+	// lexer.reader's bufio buffer is already positioned past the header and
+	// newline we just consumed; reading the underlying file again from its
+	// current offset would skip or duplicate those buffered bytes.
 	if _, err := io.ReadFull(lexer.reader, data); err != nil {
 		return StreamObject{}, err
 	}
 
-	// 4.5. Decrypt data BEFORE decompression (if encrypted)
+	// 4.5. Decrypt data BEFORE decompression (if encrypted). Most streams use
+	// the document's default /StmF (or /EFF for embedded files); a stream
+	// naming its own /Crypt filter overrides that.
 	if r.encryptHandler != nil {
-		decrypted, err := r.encryptHandler.Decrypt(data, objNum, genNum)
+		var decrypted []byte
+		var err error
+		if filterName, explicit := streamCryptFilterName(dict, r); explicit {
+			decrypted, err = r.encryptHandler.DecryptStream(data, objNum, genNum, filterName)
+		} else if t, ok := dict["/Type"].(NameObject); ok && string(t) == "/EmbeddedFile" {
+			decrypted, err = r.encryptHandler.Decrypt(data, objNum, genNum, CryptFilterScopeEmbeddedFile)
+		} else {
+			decrypted, err = r.encryptHandler.Decrypt(data, objNum, genNum, CryptFilterScopeStream)
+		}
 		if err == nil {
 			data = decrypted
 		}
@@ -161,35 +229,14 @@ func (r *Reader) readStream(dict DictionaryObject, lexer *Lexer, objNum, genNum
 		// Decompression will likely fail, but we'll handle that gracefully
 	}
 
-	// 5. Decompress
-	finalData := data
-	filterObj := r.Resolve(dict["/Filter"])
-	filters := []string{}
-
-	if name, ok := filterObj.(NameObject); ok {
-		filters = append(filters, string(name))
-	} else if arr, ok := filterObj.(ArrayObject); ok {
-		for _, f := range arr {
-			if name, ok := f.(NameObject); ok {
-				filters = append(filters, string(name))
-			}
-		}
-	}
-
-	for _, f := range filters {
-		if f == "/FlateDecode" {
-			zr, err := zlib.NewReader(bytes.NewReader(finalData))
-			if err != nil {
-				// Don't fail completely on zlib error, return raw data so we can debug
-				// or maybe it wasn't compressed.
-				return StreamObject{Dictionary: dict, Data: finalData}, nil
-			}
-			decompressed, err := io.ReadAll(zr)
-			zr.Close()
-			if err == nil {
-				finalData = decompressed
-			}
-		}
+	// 5. Decompress by walking the full /Filter chain (FlateDecode,
+	// LZWDecode, the ASCII/RunLength text filters, and pass-through image
+	// codecs), applying /DecodeParms positionally.
+	finalData, err := DecodeChain(data, r.Resolve(dict["/Filter"]), r.Resolve(dict["/DecodeParms"]))
+	if err != nil {
+		// Don't fail completely on a decode error, return raw data so callers
+		// can still inspect the dictionary (and we can debug the stream).
+		return StreamObject{Dictionary: dict, Data: data}, nil
 	}
 
 	return StreamObject{
@@ -272,8 +319,34 @@ func (r *Reader) findPage(node DictionaryObject, targetIndex *int) (DictionaryOb
 }
 
 func (r *Reader) getCompressedObject(streamObjNum int, index int) (Object, error) {
-	// Get the object stream itself
-	// This calls GetObject -> readStream, so fixing readStream fixes this too.
+	stm, err := r.loadObjStm(streamObjNum)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(stm.offsets) {
+		return nil, fmt.Errorf("object index %d out of bounds [0, %d)", index, len(stm.offsets))
+	}
+
+	stmReader := bytes.NewReader(stm.data)
+	stmReader.Seek(int64(stm.offsets[index]), io.SeekStart)
+
+	objLexer := NewLexer(stmReader)
+	return objLexer.ReadObject()
+}
+
+// loadObjStm fetches and decodes the /ObjStm with the given object number,
+// caching the result so that resolving many objects out of the same stream
+// (the common case when a page's fonts and contents all live in one
+// container) only pays the fetch+decode cost once.
+func (r *Reader) loadObjStm(streamObjNum int) (*decodedObjStm, error) {
+	r.objStmMu.Lock()
+	if cached, ok := r.objStmCache[streamObjNum]; ok {
+		r.objStmMu.Unlock()
+		return cached, nil
+	}
+	r.objStmMu.Unlock()
+
 	objStream, err := r.GetObject(IndirectObject{ObjectNumber: streamObjNum, Generation: 0})
 	if err != nil {
 		return nil, err
@@ -296,13 +369,11 @@ func (r *Reader) getCompressedObject(streamObjNum int, index int) (Object, error
 	}
 	first := int(firstObj)
 
-	// Create a lexer for the UNCOMPRESSED content
-	stmReader := bytes.NewReader(stm.Data)
-	stmLexer := NewLexer(stmReader)
+	// The header is `N` pairs of "objNum offset", offset relative to /First.
+	stmLexer := NewLexer(bytes.NewReader(stm.Data))
 
 	offsets := make([]int, n)
 	for i := 0; i < n; i++ {
-		// Read object number
 		objNum, err := stmLexer.ReadObject()
 		if err != nil {
 			return nil, fmt.Errorf("failed to read object number at index %d: %w", i, err)
@@ -311,39 +382,46 @@ func (r *Reader) getCompressedObject(streamObjNum int, index int) (Object, error
 			return nil, fmt.Errorf("unexpected nil object number at index %d", i)
 		}
 
-		// Read offset
 		offsetObj, err := stmLexer.ReadObject()
 		if err != nil {
 			return nil, fmt.Errorf("failed to read offset at index %d: %w", i, err)
 		}
-		if offsetObj == nil {
-			return nil, fmt.Errorf("unexpected nil offset at index %d", i)
-		}
-
-		// Type assert to NumberObject
 		offset, ok := offsetObj.(NumberObject)
 		if !ok {
 			return nil, fmt.Errorf("expected NumberObject for offset at index %d, got %T", i, offsetObj)
 		}
-		offsets[i] = int(offset)
+		offsets[i] = first + int(offset)
 	}
 
-	if index < 0 || index >= n {
-		return nil, fmt.Errorf("object index %d out of bounds [0, %d)", index, n)
-	}
+	decoded := &decodedObjStm{data: stm.Data, offsets: offsets}
+	r.objStmMu.Lock()
+	r.objStmCache[streamObjNum] = decoded
+	r.objStmMu.Unlock()
+	return decoded, nil
+}
 
-	startOffset := int64(first + offsets[index])
-	stmReader.Seek(startOffset, io.SeekStart)
+// GetCachedFont returns the previously-parsed Font for the dictionary at
+// objNum, or nil if loadFont hasn't parsed it yet on this Reader.
+func (r *Reader) GetCachedFont(objNum int) *Font {
+	r.fontMu.Lock()
+	defer r.fontMu.Unlock()
+	return r.fontCache[objNum]
+}
 
-	objLexer := NewLexer(stmReader)
-	return objLexer.ReadObject()
+// CacheFont records f as the parsed Font for the dictionary at objNum, so
+// later GetCachedFont calls (from this or another concurrent Extractor
+// sharing the Reader) skip re-parsing it.
+func (r *Reader) CacheFont(objNum int, f *Font) {
+	r.fontMu.Lock()
+	defer r.fontMu.Unlock()
+	r.fontCache[objNum] = f
 }
 
 func (r *Reader) Resolve(obj Object) Object {
 	if ref, ok := obj.(IndirectObject); ok {
 		res, err := r.GetObject(ref)
 		if err != nil {
-			fmt.Printf("Warning: failed to resolve object %v: %v\n", ref, err)
+			log.Printf("pdf: failed to resolve object %v: %v", ref, err)
 			return NullObject{}
 		}
 		return res
@@ -367,6 +445,53 @@ func (r *Reader) IsEncrypted() bool {
 	return exists
 }
 
+// IsLinearized reports whether the file opens with a linearization
+// parameter dictionary (the "/Linearized 1 ... " object every linearized
+// writer puts first, so readers can start rendering page 1 before the
+// rest of the file arrives). We don't do anything with the hint streams
+// it advertises — ParseXRef already walks the full /Prev chain from the
+// trailing startxref regardless of linearization — this just surfaces the
+// fact for callers that want to report it.
+func (r *Reader) IsLinearized() bool {
+	buf := make([]byte, 2048)
+	if int64(len(buf)) > r.size {
+		buf = buf[:r.size]
+	}
+	n, err := r.ra.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return false
+	}
+	return bytes.Contains(buf[:n], []byte("/Linearized"))
+}
+
+// streamCryptFilterName looks for an explicit /Crypt filter in a stream's
+// own /Filter chain - used by embedded files and any other stream that
+// opts out of the document's default /StmF - and returns the /CF key named
+// by its /DecodeParms /Name, defaulting to /Identity per spec when /Name is
+// absent. explicit is false when the stream has no /Crypt filter at all, in
+// which case the caller should fall back to the document-wide scope.
+func streamCryptFilterName(dict DictionaryObject, r *Reader) (name string, explicit bool) {
+	filters := filterNames(r.Resolve(dict["/Filter"]))
+	idx := -1
+	for i, f := range filters {
+		if f == "/Crypt" {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return "", false
+	}
+
+	parms := decodeParmsList(r.Resolve(dict["/DecodeParms"]), len(filters))
+	if idx < len(parms) && parms[idx] != nil {
+		if n, ok := parms[idx]["/Name"].(NameObject); ok {
+			return string(n), true
+		}
+	}
+	return "/Identity", true
+}
+
 // isMetadataKey checks if a dictionary key should not be encrypted
 func isMetadataKey(key string) bool {
 	// These keys are never encrypted per PDF spec
@@ -402,14 +527,14 @@ func (r *Reader) decryptObject(obj Object, objNum, genNum int) Object {
 
 	switch v := obj.(type) {
 	case StringObject:
-		decrypted, err := r.encryptHandler.Decrypt([]byte(v), objNum, genNum)
+		decrypted, err := r.encryptHandler.Decrypt([]byte(v), objNum, genNum, CryptFilterScopeString)
 		if err != nil {
 			return v // Return original on error
 		}
 		return StringObject(decrypted)
 
 	case HexStringObject:
-		decrypted, err := r.encryptHandler.Decrypt([]byte(v), objNum, genNum)
+		decrypted, err := r.encryptHandler.Decrypt([]byte(v), objNum, genNum, CryptFilterScopeString)
 		if err != nil {
 			return v
 		}
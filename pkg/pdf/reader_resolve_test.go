@@ -0,0 +1,41 @@
+package pdf
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+// TestResolveDanglingReferenceDoesNotWriteStdout is a regression test for
+// Resolve's failure path writing its warning straight to stdout via
+// fmt.Printf: --ndjson mode writes one JSON record per line to stdout, so
+// any such line interleaved into that stream would break a downstream
+// line-based JSON parser. The warning should go to the log package (which
+// defaults to stderr) instead.
+func TestResolveDanglingReferenceDoesNotWriteStdout(t *testing.T) {
+	r := &Reader{xref: NewXRefTable()}
+
+	realStdout := os.Stdout
+	read, write, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	os.Stdout = write
+	defer func() { os.Stdout = realStdout }()
+
+	result := r.Resolve(IndirectObject{ObjectNumber: 9999})
+
+	write.Close()
+	os.Stdout = realStdout
+	captured, err := io.ReadAll(read)
+	if err != nil {
+		t.Fatalf("reading captured stdout: %v", err)
+	}
+
+	if _, ok := result.(NullObject); !ok {
+		t.Fatalf("Resolve(dangling ref) = %T, want NullObject", result)
+	}
+	if len(captured) != 0 {
+		t.Fatalf("Resolve wrote to stdout: %q", captured)
+	}
+}
@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+
+	"github.com/AOShei/pdf-loader/pkg/model"
+)
+
+// RenderPage composites a page's extracted images onto a blank canvas sized
+// to its MediaBox, producing a raster preview of the page's image layer.
+// This extractor works from the content stream's text/image operators
+// rather than a full path-filling renderer, so vector graphics and text are
+// not rasterized here - a text-only page will render as a blank canvas.
+func RenderPage(page model.Page) (image.Image, error) {
+	w, h := int(math.Ceil(page.Width)), int(math.Ceil(page.Height))
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("page has no usable dimensions (%gx%g)", page.Width, page.Height)
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	if page.Images == nil {
+		return canvas, nil
+	}
+
+	for _, img := range *page.Images {
+		// A single malformed or unsupported image shouldn't blank out the
+		// rest of the page's layout.
+		_ = drawImageLayer(canvas, page.Height, img)
+	}
+
+	return canvas, nil
+}
+
+// drawImageLayer decodes one extracted image and draws it into canvas at
+// its recorded page-space Rect, flipped into raster (y-down) coordinates.
+func drawImageLayer(canvas *image.RGBA, pageHeight float64, img model.Image) error {
+	if img.IsMask || img.Data == "" || len(img.Rect) != 4 {
+		return nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(img.Data)
+	if err != nil {
+		return fmt.Errorf("failed to decode image data: %w", err)
+	}
+
+	var src image.Image
+	switch img.Format {
+	case "jpeg":
+		if src, err = jpeg.Decode(bytes.NewReader(raw)); err != nil {
+			return fmt.Errorf("failed to decode jpeg image: %w", err)
+		}
+	default:
+		// Raw/CCITT/JPX samples aren't unpacked into pixels here; skip
+		// rather than guess at a bit-packing scheme we'd likely get wrong.
+		return nil
+	}
+
+	x, y, rw, rh := img.Rect[0], img.Rect[1], img.Rect[2], img.Rect[3]
+	// PDF page space has y increasing upward; image.RGBA has y increasing
+	// downward, so the rect is flipped around the page height.
+	dstRect := image.Rect(
+		int(math.Round(x)), int(math.Round(pageHeight-y-rh)),
+		int(math.Round(x+rw)), int(math.Round(pageHeight-y)),
+	)
+	drawScaled(canvas, dstRect, src)
+	return nil
+}
+
+// drawScaled nearest-neighbor scales src into dst at dstRect. Points in
+// dstRect outside dst's own bounds are silently dropped by RGBA.Set, so no
+// separate clipping is needed.
+func drawScaled(dst *image.RGBA, dstRect image.Rectangle, src image.Image) {
+	sb := src.Bounds()
+	dw, dh := dstRect.Dx(), dstRect.Dy()
+	if dw <= 0 || dh <= 0 || sb.Dx() <= 0 || sb.Dy() <= 0 {
+		return
+	}
+	for dy := 0; dy < dh; dy++ {
+		sy := sb.Min.Y + dy*sb.Dy()/dh
+		for dx := 0; dx < dw; dx++ {
+			sx := sb.Min.X + dx*sb.Dx()/dw
+			dst.Set(dstRect.Min.X+dx, dstRect.Min.Y+dy, src.At(sx, sy))
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+)
+
+// signatureContentsSize is the byte length reserved for the detached PKCS#7
+// blob (twice that many hex characters inside the /Contents string). 8KB
+// of DER comfortably fits one signer + a handful of intermediate certs;
+// Sign errors out rather than truncate if the real signature is bigger.
+const signatureContentsSize = 8192
+
+// Sign appends an incremental revision containing an AcroForm signature
+// field covering the entire document, then patches the real /ByteRange and
+// a detached PKCS#7 (CMS SignedData) signature into the placeholders left
+// in that revision. This is the standard two-pass approach: the exact
+// /ByteRange can't be known until the signature dictionary's own offset and
+// size are fixed by laying out the revision once.
+func (r *Reader) Sign(cert *x509.Certificate, key crypto.Signer) ([]byte, error) {
+	if _, ok := r.xref.Trailer["/Root"]; !ok {
+		return nil, fmt.Errorf("cannot sign: document has no /Root")
+	}
+
+	rootRef, ok := r.xref.Trailer["/Root"].(IndirectObject)
+	if !ok {
+		return nil, fmt.Errorf("cannot sign: /Root is not an indirect reference")
+	}
+
+	page, err := r.GetPage(0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign: failed to locate page 1: %w", err)
+	}
+	pageRef, ok := findObjectRef(r, rootRef, page)
+	if !ok {
+		return nil, fmt.Errorf("cannot sign: could not resolve page 1's own object number")
+	}
+
+	// Allocate fresh object numbers above anything already in the xref
+	// table for the signature dict, the widget annotation, and the
+	// AcroForm dictionary.
+	nextNum := 1
+	for num := range r.xref.Entries {
+		if num >= nextNum {
+			nextNum = num + 1
+		}
+	}
+	sigObjNum := nextNum
+	widgetObjNum := nextNum + 1
+	acroFormObjNum := nextNum + 2
+
+	contentsPlaceholder := bytes.Repeat([]byte{0}, signatureContentsSize)
+	sigDict := DictionaryObject{
+		"/Type":      NameObject("/Sig"),
+		"/Filter":    NameObject("/Adobe.PPKLite"),
+		"/SubFilter": NameObject("/adbe.pkcs7.detached"),
+		"/ByteRange": ArrayObject{NumberObject(0), NumberObject(0), NumberObject(0), NumberObject(0)},
+		"/Contents":  HexStringObject(contentsPlaceholder),
+	}
+
+	widgetDict := DictionaryObject{
+		"/Type":    NameObject("/Annot"),
+		"/Subtype": NameObject("/Widget"),
+		"/FT":      NameObject("/Sig"),
+		"/Rect":    ArrayObject{NumberObject(0), NumberObject(0), NumberObject(0), NumberObject(0)},
+		"/P":       pageRef,
+		"/V":       IndirectObject{ObjectNumber: sigObjNum, Generation: 0},
+		"/F":       NumberObject(132), // Print(4) | Locked(128), matching typical signed-field flags
+	}
+
+	acroFormDict := DictionaryObject{
+		"/Fields":   ArrayObject{IndirectObject{ObjectNumber: widgetObjNum, Generation: 0}},
+		"/SigFlags": NumberObject(3), // SignaturesExist | AppendOnly
+	}
+
+	updatedPage := make(DictionaryObject, len(page)+1)
+	for k, v := range page {
+		updatedPage[k] = v
+	}
+	annots, _ := r.Resolve(page["/Annots"]).(ArrayObject)
+	updatedPage["/Annots"] = append(append(ArrayObject{}, annots...), IndirectObject{ObjectNumber: widgetObjNum, Generation: 0})
+
+	catalog, _ := r.Resolve(rootRef).(DictionaryObject)
+	updatedCatalog := make(DictionaryObject, len(catalog)+1)
+	for k, v := range catalog {
+		updatedCatalog[k] = v
+	}
+	updatedCatalog["/AcroForm"] = IndirectObject{ObjectNumber: acroFormObjNum, Generation: 0}
+
+	newObjects := map[int]Object{
+		sigObjNum:         sigDict,
+		widgetObjNum:      widgetDict,
+		acroFormObjNum:    acroFormDict,
+		pageRef.(IndirectObject).ObjectNumber: updatedPage,
+		rootRef.ObjectNumber:                  updatedCatalog,
+	}
+
+	revision, err := r.AppendRevision(newObjects, UpdateOptions{XRefStream: false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lay out signature revision: %w", err)
+	}
+
+	return patchSignaturePlaceholders(revision, cert, key)
+}
+
+// findObjectRef locates the indirect reference of the /Page dictionary whose
+// serialized form equals target's, by scanning every xref entry; root is not
+// consulted. Used to recover a page's own object number, which GetPage's
+// tree walk doesn't track.
+func findObjectRef(r *Reader, root IndirectObject, target DictionaryObject) (Object, bool) {
+	for num := range r.xref.Entries {
+		candidate, err := r.GetObject(IndirectObject{ObjectNumber: num, Generation: 0})
+		if err != nil {
+			continue
+		}
+		dict, ok := candidate.(DictionaryObject)
+		if !ok {
+			continue
+		}
+		if dict["/Type"].String() == "/Page" && dict.String() == target.String() {
+			return IndirectObject{ObjectNumber: num, Generation: 0}, true
+		}
+	}
+	return nil, false
+}
+
+// patchSignaturePlaceholders locates the /Contents hex string and
+// /ByteRange array written by Sign, fills in the real byte range, computes
+// the detached PKCS#7 signature over everything except the /Contents
+// placeholder, and writes it into that same fixed-width hex string so the
+// overall file length never changes after this point.
+func patchSignaturePlaceholders(revision []byte, cert *x509.Certificate, key crypto.Signer) ([]byte, error) {
+	contentsMarker := []byte("/Contents <")
+	contentsStart := bytes.Index(revision, contentsMarker)
+	if contentsStart == -1 {
+		return nil, fmt.Errorf("signature placeholder /Contents not found in revision")
+	}
+	hexStart := contentsStart + len(contentsMarker)
+	hexEnd := bytes.IndexByte(revision[hexStart:], '>')
+	if hexEnd == -1 {
+		return nil, fmt.Errorf("malformed /Contents placeholder")
+	}
+	hexEnd += hexStart
+
+	byteRangeMarker := []byte("/ByteRange [0 0 0 0]")
+	byteRangeStart := bytes.Index(revision, byteRangeMarker)
+	if byteRangeStart == -1 {
+		return nil, fmt.Errorf("signature placeholder /ByteRange not found in revision")
+	}
+
+	range1End := hexStart - 1 // up to (not including) the opening '<'
+	range2Start := hexEnd + 1 // just past the closing '>'
+	byteRange := fmt.Sprintf("/ByteRange [0 %d %d %d]", range1End, range2Start, len(revision)-range2Start)
+	if len(byteRange) > len(byteRangeMarker) {
+		return nil, fmt.Errorf("/ByteRange placeholder too small for real values")
+	}
+	copy(revision[byteRangeStart:], byteRange)
+	for i := byteRangeStart + len(byteRange); i < byteRangeStart+len(byteRangeMarker); i++ {
+		revision[i] = ' '
+	}
+
+	signedContent := make([]byte, 0, range1End+(len(revision)-range2Start))
+	signedContent = append(signedContent, revision[:range1End]...)
+	signedContent = append(signedContent, revision[range2Start:]...)
+
+	der, err := signPKCS7Detached(signedContent, cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build PKCS#7 signature: %w", err)
+	}
+	if len(der)*2 > hexEnd-hexStart {
+		return nil, fmt.Errorf("signature (%d bytes) exceeds reserved /Contents space", len(der))
+	}
+
+	hexDigits := []byte("0123456789abcdef")
+	for i := range revision[hexStart:hexEnd] {
+		revision[hexStart+i] = '0'
+	}
+	for i, b := range der {
+		revision[hexStart+i*2] = hexDigits[b>>4]
+		revision[hexStart+i*2+1] = hexDigits[b&0x0F]
+	}
+
+	return revision, nil
+}
+
+// --- Minimal PKCS#7/CMS SignedData (RFC 5652) for detached signing ---
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue `asn1:"set"`
+	ContentInfo      pkcs7ContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	SignerInfos      asn1.RawValue `asn1:"set"`
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+}
+
+var (
+	oidData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncrypt = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+
+	// asnNull is the DER encoding of an ASN.1 NULL, used as the (optional
+	// but conventionally present) parameters field of an AlgorithmIdentifier.
+	asnNull = asn1.RawValue{FullBytes: []byte{0x05, 0x00}}
+)
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue
+}
+
+type signerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     issuerAndSerial
+	DigestAlgorithm           algorithmIdentifier
+	DigestEncryptionAlgorithm algorithmIdentifier
+	EncryptedDigest           []byte
+}
+
+type issuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber asn1.RawValue
+}
+
+// signPKCS7Detached builds a detached CMS SignedData blob: no encapsulated
+// content (the document bytes live in the PDF, referenced only by
+// /ByteRange), one signer, one certificate, SHA-256 digest, PKCS#1 v1.5
+// signature. This covers the pdf-simple-sign workflow's needs without
+// pulling in a third-party CMS library.
+func signPKCS7Detached(content []byte, cert *x509.Certificate, key crypto.Signer) ([]byte, error) {
+	digest := sha256.Sum256(content)
+
+	sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("signing digest: %w", err)
+	}
+
+	// cert.RawIssuer is already the DER-encoded Name SEQUENCE.
+	issuerSeq := asn1.RawValue{FullBytes: cert.RawIssuer}
+
+	serial, err := asn1.Marshal(cert.SerialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	info := signerInfo{
+		Version: 1,
+		IssuerAndSerialNumber: issuerAndSerial{
+			Issuer:       issuerSeq,
+			SerialNumber: asn1.RawValue{FullBytes: serial},
+		},
+		DigestAlgorithm:           algorithmIdentifier{Algorithm: oidSHA256, Parameters: asnNull},
+		DigestEncryptionAlgorithm: algorithmIdentifier{Algorithm: oidRSAEncrypt, Parameters: asnNull},
+		EncryptedDigest:           sig,
+	}
+	signerInfoBytes, err := asn1.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling signerInfo: %w", err)
+	}
+
+	digestAlgSet, err := asn1.MarshalWithParams([]algorithmIdentifier{{Algorithm: oidSHA256, Parameters: asnNull}}, "set")
+	if err != nil {
+		return nil, err
+	}
+	signerInfosSet, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: signerInfoBytes}}, "set")
+	if err != nil {
+		return nil, err
+	}
+
+	// Certificates is "[0] IMPLICIT SET OF Certificate": the context tag
+	// replaces the SET's own universal tag, so it wraps cert.Raw directly
+	// rather than a separately-marshaled universal SET. A RawValue with
+	// FullBytes set is emitted verbatim (encoding/asn1 ignores Class/Tag/
+	// IsCompound whenever FullBytes is non-empty), so the wrapping has to
+	// go through Bytes instead.
+	signedData := pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{FullBytes: digestAlgSet},
+		ContentInfo:      pkcs7ContentInfo{ContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: cert.Raw},
+		SignerInfos:      asn1.RawValue{FullBytes: signerInfosSet},
+	}
+	signedDataBytes, err := asn1.Marshal(signedData)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling SignedData: %w", err)
+	}
+
+	// Same FullBytes-vs-Bytes caveat as Certificates above: this field is
+	// "[0] EXPLICIT ANY", so the context tag has to be built via Bytes to
+	// actually wrap signedDataBytes instead of being discarded.
+	outer := struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"explicit,tag:0"`
+	}{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedDataBytes},
+	}
+	return asn1.Marshal(outer)
+}
@@ -0,0 +1,257 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/lzw"
+	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rc4"
+	"fmt"
+	"io"
+)
+
+// OpenStream returns a streaming reader over the raw stream data of the
+// object at ref, decrypting and decompressing bytes as they're consumed
+// instead of buffering the whole stream in memory the way GetObject/
+// readStream do. This matters for multi-hundred-MB content streams and
+// embedded files, where eagerly decoding into a []byte can cost hundreds of
+// MB just to pull a few pages of text out.
+//
+// Only the common case actually streams: a single predictor-free
+// /FlateDecode or /LZWDecode filter, optionally RC4 or AES encrypted.
+// Anything else - multiple chained filters, a PNG/TIFF predictor, or an
+// object living inside an /ObjStm - falls back to the eager GetObject path
+// wrapped in a reader, so callers get the same bytes either way and only
+// the memory profile differs.
+func (r *Reader) OpenStream(ref IndirectObject) (io.ReadCloser, error) {
+	entry, ok := r.xref.Entries[ref.ObjectNumber]
+	if !ok {
+		return nil, fmt.Errorf("object %d not found in xref", ref.ObjectNumber)
+	}
+	if entry.Free || entry.Compressed {
+		return r.openStreamEager(ref)
+	}
+
+	lexer := NewLexer(r.sectionFrom(entry.Offset))
+	lexer.ReadObject() // objNum
+	lexer.ReadObject() // gen
+	lexer.ReadObject() // "obj" keyword
+
+	obj, err := lexer.ReadObject()
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := obj.(DictionaryObject)
+	if !ok {
+		return nil, fmt.Errorf("object %d is not a stream", ref.ObjectNumber)
+	}
+
+	lexer.skipWhitespace()
+	peek, _ := lexer.reader.Peek(6)
+	if string(peek) != "stream" {
+		return nil, fmt.Errorf("object %d has no stream body", ref.ObjectNumber)
+	}
+
+	lengthObj, ok := r.Resolve(dict["/Length"]).(NumberObject)
+	if !ok {
+		return r.openStreamEager(ref)
+	}
+
+	filterObj := r.Resolve(dict["/Filter"])
+	if !canStreamFilters(filterObj, r.Resolve(dict["/DecodeParms"])) {
+		return r.openStreamEager(ref)
+	}
+
+	lexer.reader.Discard(6)
+	b, err := lexer.reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch b {
+	case '\r':
+		if next, _ := lexer.reader.Peek(1); len(next) > 0 && next[0] == '\n' {
+			lexer.reader.ReadByte()
+		}
+	case '\n':
+		// OK - standard LF
+	default:
+		lexer.reader.UnreadByte()
+	}
+
+	raw := io.Reader(io.LimitReader(lexer.reader, int64(lengthObj)))
+
+	if r.encryptHandler != nil {
+		cfm := ""
+		if filterName, explicit := streamCryptFilterName(dict, r); explicit {
+			cfm = r.encryptHandler.cfmForFilterName(filterName)
+		} else if t, ok := dict["/Type"].(NameObject); ok && string(t) == "/EmbeddedFile" {
+			cfm = r.encryptHandler.cfmForScope(CryptFilterScopeEmbeddedFile)
+		} else {
+			cfm = r.encryptHandler.cfmForScope(CryptFilterScopeStream)
+		}
+
+		decrypted, err := streamingDecrypt(raw, r.encryptHandler, ref.ObjectNumber, ref.Generation, cfm)
+		if err != nil {
+			// The stream's algorithm isn't one we know how to decrypt
+			// incrementally; fall back rather than hand back ciphertext.
+			return r.openStreamEager(ref)
+		}
+		raw = decrypted
+	}
+
+	decoded, err := streamingDecode(raw, filterObj)
+	if err != nil {
+		return r.openStreamEager(ref)
+	}
+	return decoded, nil
+}
+
+// openStreamEager is the fallback path: decode the stream the normal,
+// in-memory way and hand its bytes back through the same io.ReadCloser
+// interface OpenStream uses for the streaming path.
+func (r *Reader) openStreamEager(ref IndirectObject) (io.ReadCloser, error) {
+	obj, err := r.GetObject(ref)
+	if err != nil {
+		return nil, err
+	}
+	stm, ok := obj.(StreamObject)
+	if !ok {
+		return nil, fmt.Errorf("object %d is not a stream", ref.ObjectNumber)
+	}
+	return io.NopCloser(bytes.NewReader(stm.Data)), nil
+}
+
+// canStreamFilters reports whether a stream's filter chain is simple enough
+// to decode incrementally: at most one filter, and that filter either
+// FlateDecode or LZWDecode with no predictor (PNG/TIFF predictors need the
+// previous row in hand, which the streaming path doesn't track).
+func canStreamFilters(filterObj, decodeParmsObj Object) bool {
+	filters := filterNames(filterObj)
+	if len(filters) > 1 {
+		return false
+	}
+	for _, f := range filters {
+		if f != "/FlateDecode" && f != "/Fl" && f != "/LZWDecode" && f != "/LZW" {
+			return false
+		}
+	}
+	for _, parms := range decodeParmsList(decodeParmsObj, len(filters)) {
+		if p, ok := parms["/Predictor"].(NumberObject); ok && int(p) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// streamingDecrypt wraps src in a decrypting reader for the given crypt
+// filter method, or returns src unchanged for /Identity. RC4 is a stream
+// cipher already, so cipher.StreamReader is exact; AES-CBC needs the
+// block-at-a-time reader below to strip PKCS7 padding without buffering the
+// whole plaintext.
+func streamingDecrypt(src io.Reader, h *EncryptionHandler, objNum, genNum int, cfm string) (io.Reader, error) {
+	switch cfm {
+	case "/Identity", "/None", "":
+		return src, nil
+	case "/V2":
+		c, err := rc4.NewCipher(h.computeObjectKey(objNum, genNum))
+		if err != nil {
+			return nil, err
+		}
+		return &cipher.StreamReader{S: c, R: src}, nil
+	case "/AESV2":
+		return newAESCBCReader(h.computeObjectKey(objNum, genNum), src)
+	case "/AESV3":
+		return newAESCBCReader(h.EncryptKey, src)
+	default:
+		return nil, fmt.Errorf("unsupported crypt filter method for streaming: %s", cfm)
+	}
+}
+
+// streamingDecode wraps the already-decrypted reader src in the single
+// decompressor canStreamFilters approved.
+func streamingDecode(src io.Reader, filterObj Object) (io.ReadCloser, error) {
+	filters := filterNames(filterObj)
+	if len(filters) == 0 {
+		return io.NopCloser(src), nil
+	}
+	switch filters[0] {
+	case "/FlateDecode", "/Fl":
+		return zlib.NewReader(src)
+	case "/LZWDecode", "/LZW":
+		return lzw.NewReader(src, lzw.MSB, 8), nil
+	default:
+		return nil, fmt.Errorf("unsupported filter for streaming: %s", filters[0])
+	}
+}
+
+// aesCBCReader streams AES-CBC decryption one block at a time, reading the
+// IV up front and keeping a single block of lookahead ciphertext so it can
+// tell when it's decrypting the final block and strip its PKCS7 padding -
+// all without holding the full plaintext in memory at once.
+type aesCBCReader struct {
+	mode      cipher.BlockMode
+	src       io.Reader
+	lookahead []byte
+	haveLook  bool
+	out       []byte
+	err       error
+}
+
+func newAESCBCReader(key []byte, src io.Reader) (io.Reader, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(src, iv); err != nil {
+		return nil, fmt.Errorf("reading AES IV: %w", err)
+	}
+
+	r := &aesCBCReader{mode: cipher.NewCBCDecrypter(block, iv), src: src}
+	lookahead := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(src, lookahead); err != nil {
+		r.err = io.EOF
+		return r, nil
+	}
+	r.lookahead = lookahead
+	r.haveLook = true
+	return r, nil
+}
+
+func (r *aesCBCReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if !r.haveLook {
+			if r.err != nil {
+				return 0, r.err
+			}
+			return 0, io.EOF
+		}
+
+		ct := r.lookahead
+		next := make([]byte, aes.BlockSize)
+		n, err := io.ReadFull(r.src, next)
+		isLast := err != nil || n < aes.BlockSize
+		if isLast {
+			r.haveLook = false
+			r.err = io.EOF
+		} else {
+			r.lookahead = next
+		}
+
+		pt := make([]byte, aes.BlockSize)
+		r.mode.CryptBlocks(pt, ct)
+		if isLast {
+			stripped, err := removePadding(pt)
+			if err != nil {
+				return 0, err
+			}
+			pt = stripped
+		}
+		r.out = pt
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
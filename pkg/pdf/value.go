@@ -0,0 +1,194 @@
+package pdf
+
+import "sort"
+
+// Kind identifies which concrete PDF object type a Value currently wraps.
+type Kind int
+
+const (
+	KindInvalid Kind = iota
+	KindNull
+	KindBool
+	KindNumber
+	KindString
+	KindName
+	KindArray
+	KindDictionary
+	KindStream
+)
+
+// Value is an ergonomic, panic-free wrapper over a (possibly indirect) PDF
+// object and the *Reader it came from. Every accessor resolves an
+// IndirectObject automatically and returns ok=false (or a zero Value, for
+// Key/Index) instead of panicking when the wrapped object isn't of the
+// requested type - the same "hand-rolled .(FooObject) type assertion"
+// callers throughout pkg/loader and pkg/pdf/extractor already do, just
+// without repeating the resolve-then-assert dance at every call site.
+type Value struct {
+	obj    Object
+	reader *Reader
+}
+
+// Value wraps obj as a Value bound to r, so its accessors resolve any
+// IndirectObject reference before inspecting it.
+func (r *Reader) Value(obj Object) Value {
+	return Value{obj: obj, reader: r}
+}
+
+// resolved follows obj through the Reader, if any; Value's zero value has
+// no reader and no object, so every accessor degrades to "not found"
+// rather than dereferencing a nil Reader.
+func (v Value) resolved() Object {
+	if v.reader == nil {
+		return v.obj
+	}
+	return v.reader.Resolve(v.obj)
+}
+
+// Kind reports which concrete object Value currently wraps, resolving
+// indirect references first.
+func (v Value) Kind() Kind {
+	switch v.resolved().(type) {
+	case NullObject:
+		return KindNull
+	case BooleanObject:
+		return KindBool
+	case NumberObject:
+		return KindNumber
+	case StringObject, HexStringObject:
+		return KindString
+	case NameObject:
+		return KindName
+	case ArrayObject:
+		return KindArray
+	case DictionaryObject:
+		return KindDictionary
+	case StreamObject:
+		return KindStream
+	default:
+		return KindInvalid
+	}
+}
+
+// Int64 returns the wrapped value as an integer, or ok=false if it isn't a
+// NumberObject.
+func (v Value) Int64() (int64, bool) {
+	n, ok := v.resolved().(NumberObject)
+	if !ok {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+// Float64 returns the wrapped value as a float, or ok=false if it isn't a
+// NumberObject.
+func (v Value) Float64() (float64, bool) {
+	n, ok := v.resolved().(NumberObject)
+	if !ok {
+		return 0, false
+	}
+	return float64(n), true
+}
+
+// Bool returns the wrapped value as a bool, or ok=false if it isn't a
+// BooleanObject.
+func (v Value) Bool() (bool, bool) {
+	b, ok := v.resolved().(BooleanObject)
+	if !ok {
+		return false, false
+	}
+	return bool(b), true
+}
+
+// Name returns the wrapped value's name (without the leading "/" stripped -
+// NameObject already stores it that way throughout this package), or
+// ok=false if it isn't a NameObject.
+func (v Value) Name() (string, bool) {
+	n, ok := v.resolved().(NameObject)
+	if !ok {
+		return "", false
+	}
+	return string(n), true
+}
+
+// String returns the wrapped value's text, accepting either a literal or
+// hex PDF string, or ok=false for any other kind.
+func (v Value) String() (string, bool) {
+	switch s := v.resolved().(type) {
+	case StringObject:
+		return string(s), true
+	case HexStringObject:
+		return string(s), true
+	}
+	return "", false
+}
+
+// Key looks up name in the wrapped dictionary (or a stream's dictionary)
+// and returns it as a Value bound to the same Reader. Looking up a missing
+// key, or calling Key on something that isn't a dictionary, yields a zero
+// Value whose own accessors all report ok=false - callers can chain
+// Key/Index freely without a nil check at every step.
+func (v Value) Key(name string) Value {
+	switch o := v.resolved().(type) {
+	case DictionaryObject:
+		return Value{obj: o[name], reader: v.reader}
+	case StreamObject:
+		return Value{obj: o.Dictionary[name], reader: v.reader}
+	}
+	return Value{reader: v.reader}
+}
+
+// Index returns the i'th element of the wrapped array as a Value, or a
+// zero Value if it isn't an array or i is out of range.
+func (v Value) Index(i int) Value {
+	arr, ok := v.resolved().(ArrayObject)
+	if !ok || i < 0 || i >= len(arr) {
+		return Value{reader: v.reader}
+	}
+	return Value{obj: arr[i], reader: v.reader}
+}
+
+// Len returns the element count of an array, the key count of a
+// dictionary, or the byte length of a string - 0 for anything else.
+func (v Value) Len() int {
+	switch o := v.resolved().(type) {
+	case ArrayObject:
+		return len(o)
+	case DictionaryObject:
+		return len(o)
+	case StringObject:
+		return len(o)
+	case HexStringObject:
+		return len(o)
+	}
+	return 0
+}
+
+// Keys returns the wrapped dictionary's keys in sorted order, or nil if
+// the wrapped value isn't a dictionary.
+func (v Value) Keys() []string {
+	dict, ok := v.resolved().(DictionaryObject)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(dict))
+	for k := range dict {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Reader returns the *Reader this Value resolves indirect references
+// through, so callers that need lower-level access (OpenStream, GetObject)
+// aren't stuck once they've drilled down with Key/Index.
+func (v Value) Reader() *Reader {
+	return v.reader
+}
+
+// Stream returns the wrapped object as a StreamObject, or ok=false if it
+// isn't one.
+func (v Value) Stream() (StreamObject, bool) {
+	stm, ok := v.resolved().(StreamObject)
+	return stm, ok
+}
@@ -0,0 +1,378 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/AOShei/pdf-loader/pkg/model"
+)
+
+var (
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSHA1          = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA384        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+// cmsOuterContentInfo is the outermost ContentInfo wrapping a SignedData,
+// as produced by signPKCS7Detached and by every other common PDF signing
+// tool.
+type cmsOuterContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+// cmsEncapContentInfo is SignedData's inner EncapsulatedContentInfo. A
+// detached signature's eContent is absent, but unlike pkcs7ContentInfo
+// (which assumes that), Content is declared OPTIONAL here so a signer that
+// does include it doesn't trip decoding.
+type cmsEncapContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional,explicit,tag:0"`
+}
+
+// cmsSignedData mirrors pkcs7SignedData but keeps SignerInfos and
+// Certificates as raw SET content so verification can walk into each
+// member, rather than only round-tripping the blob the way signing does.
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue `asn1:"set"`
+	ContentInfo      cmsEncapContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue `asn1:"set"`
+}
+
+// cmsSignerInfo is signerInfo (sign.go) plus the AuthenticatedAttributes
+// signing tools routinely add (at minimum a messageDigest and signingTime
+// attribute); EncryptedDigest is a signature over those attributes, not
+// over the content digest directly, whenever they're present.
+type cmsSignerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     issuerAndSerial
+	DigestAlgorithm           algorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm algorithmIdentifier
+	EncryptedDigest           []byte
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// VerifySignatures walks Catalog -> AcroForm -> Fields for signature
+// fields (dictionaries with /Type /Sig), verifies each embedded detached
+// PKCS#7/CMS signature against roots (the system trust store when roots is
+// nil), and returns one model.SignatureInfo per field found. A field whose
+// signature can't be parsed or verified is still reported, with Verified
+// false and Error explaining why, rather than silently dropped.
+func (r *Reader) VerifySignatures(roots *x509.CertPool) ([]model.SignatureInfo, error) {
+	if roots == nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		roots = pool
+	}
+
+	catalog, ok := r.Resolve(r.xref.Trailer["/Root"]).(DictionaryObject)
+	if !ok {
+		return nil, nil
+	}
+	acroForm, ok := r.Resolve(catalog["/AcroForm"]).(DictionaryObject)
+	if !ok {
+		return nil, nil
+	}
+	fields, ok := r.Resolve(acroForm["/Fields"]).(ArrayObject)
+	if !ok {
+		return nil, nil
+	}
+
+	var results []model.SignatureInfo
+	for _, fieldRef := range fields {
+		field, ok := r.Resolve(fieldRef).(DictionaryObject)
+		if !ok {
+			continue
+		}
+		sigDict, ok := r.Resolve(field["/V"]).(DictionaryObject)
+		if !ok {
+			continue
+		}
+		if t, ok := sigDict["/Type"].(NameObject); !ok || string(t) != "/Sig" {
+			continue
+		}
+		results = append(results, r.verifySignatureField(sigDict, roots))
+	}
+	return results, nil
+}
+
+// verifySignatureField reads one /Sig dictionary's /ByteRange and /Contents
+// off the raw file and reports the result of verifying it.
+func (r *Reader) verifySignatureField(sigDict DictionaryObject, roots *x509.CertPool) model.SignatureInfo {
+	info := model.SignatureInfo{}
+	if sf, ok := sigDict["/SubFilter"].(NameObject); ok {
+		info.SubFilter = string(sf)
+	}
+
+	byteRangeArr, ok := r.Resolve(sigDict["/ByteRange"]).(ArrayObject)
+	if !ok {
+		info.Error = "signature field missing or invalid /ByteRange"
+		return info
+	}
+	byteRange, ok := intsFromArray(byteRangeArr)
+	if !ok || len(byteRange) != 4 {
+		info.Error = "malformed /ByteRange: expected 4 direct integers"
+		return info
+	}
+	info.CoversWholeFile = byteRange[0] == 0 && int64(byteRange[2]+byteRange[3]) == r.size
+
+	der, ok := contentsBytes(r.Resolve(sigDict["/Contents"]))
+	if !ok {
+		info.Error = "signature field missing or unreadable /Contents"
+		return info
+	}
+
+	span1 := make([]byte, byteRange[1])
+	if _, err := r.ra.ReadAt(span1, int64(byteRange[0])); err != nil {
+		info.Error = fmt.Sprintf("reading first /ByteRange span: %v", err)
+		return info
+	}
+	span2 := make([]byte, byteRange[3])
+	if _, err := r.ra.ReadAt(span2, int64(byteRange[2])); err != nil {
+		info.Error = fmt.Sprintf("reading second /ByteRange span: %v", err)
+		return info
+	}
+
+	result, err := verifyPKCS7Detached(der, span1, span2)
+	info.SignerCommonName = result.commonName
+	info.SigningTime = result.signingTime
+	info.DigestAlgorithm = result.digestAlgorithm
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Verified = true
+
+	opts := x509.VerifyOptions{Roots: roots, Intermediates: result.intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}
+	if t, err := time.Parse(time.RFC3339, result.signingTime); err == nil {
+		opts.CurrentTime = t
+	}
+	if _, err := result.signerCert.Verify(opts); err == nil {
+		info.TrustedChain = true
+	}
+
+	return info
+}
+
+// contentsBytes extracts the raw bytes of a /Contents value, which is a hex
+// string per spec but is represented the same way as a literal string once
+// this package's lexer has decoded it.
+func contentsBytes(obj Object) ([]byte, bool) {
+	switch v := obj.(type) {
+	case HexStringObject:
+		return []byte(v), true
+	case StringObject:
+		return []byte(v), true
+	}
+	return nil, false
+}
+
+// pkcs7VerifyResult carries the pieces of a verified SignedData that
+// verifySignatureField needs to finish building a model.SignatureInfo and
+// to run the certificate chain check.
+type pkcs7VerifyResult struct {
+	commonName      string
+	signingTime     string
+	digestAlgorithm string
+	signerCert      *x509.Certificate
+	intermediates   *x509.CertPool
+}
+
+// verifyPKCS7Detached parses a detached PKCS#7/CMS SignedData blob (as
+// embedded in /Contents) and verifies its signature over span1+span2 (the
+// file bytes /ByteRange covers). Only RSA signer keys are supported,
+// matching the only algorithm this package's own Sign produces; anything
+// else is reported as an error rather than silently skipped.
+func verifyPKCS7Detached(der, span1, span2 []byte) (pkcs7VerifyResult, error) {
+	var result pkcs7VerifyResult
+
+	var outer cmsOuterContentInfo
+	if _, err := asn1.Unmarshal(der, &outer); err != nil {
+		return result, fmt.Errorf("parsing CMS ContentInfo: %w", err)
+	}
+	if !outer.ContentType.Equal(oidSignedData) {
+		return result, fmt.Errorf("unsupported CMS content type %v (expected SignedData)", outer.ContentType)
+	}
+
+	// Go's asn1 doesn't auto-strip explicit tagging for RawValue fields:
+	// Content.FullBytes is still the [0] wrapper (tag + length included),
+	// Content.Bytes is the unwrapped SignedData SEQUENCE inside it.
+	var sd cmsSignedData
+	if _, err := asn1.Unmarshal(outer.Content.Bytes, &sd); err != nil {
+		return result, fmt.Errorf("parsing CMS SignedData: %w", err)
+	}
+
+	signerRaws, err := splitRawElements(sd.SignerInfos.Bytes)
+	if err != nil || len(signerRaws) == 0 {
+		return result, fmt.Errorf("SignedData carries no SignerInfo")
+	}
+	var signer cmsSignerInfo
+	if _, err := asn1.Unmarshal(signerRaws[0].FullBytes, &signer); err != nil {
+		return result, fmt.Errorf("parsing SignerInfo: %w", err)
+	}
+
+	certRaws, err := splitRawElements(sd.Certificates.Bytes)
+	if err != nil || len(certRaws) == 0 {
+		return result, fmt.Errorf("SignedData carries no certificates")
+	}
+	intermediates := x509.NewCertPool()
+	for _, raw := range certRaws {
+		cert, err := x509.ParseCertificate(raw.FullBytes)
+		if err != nil {
+			return result, fmt.Errorf("parsing embedded certificate: %w", err)
+		}
+		if result.signerCert == nil && certMatchesIssuerSerial(cert, signer.IssuerAndSerialNumber) {
+			result.signerCert = cert
+			continue
+		}
+		intermediates.AddCert(cert)
+	}
+	if result.signerCert == nil {
+		return result, fmt.Errorf("no embedded certificate matches the SignerInfo's issuer/serial")
+	}
+	result.intermediates = intermediates
+	result.commonName = result.signerCert.Subject.CommonName
+
+	hashAlg, ok := hashForOID(signer.DigestAlgorithm.Algorithm)
+	if !ok {
+		return result, fmt.Errorf("unsupported digest algorithm %v", signer.DigestAlgorithm.Algorithm)
+	}
+	result.digestAlgorithm = hashAlg.String()
+
+	h := hashAlg.New()
+	h.Write(span1)
+	h.Write(span2)
+	contentDigest := h.Sum(nil)
+
+	signedBytes := contentDigest
+	if len(signer.AuthenticatedAttributes.FullBytes) > 0 {
+		signedBytes, err = digestOverAuthenticatedAttributes(signer.AuthenticatedAttributes, hashAlg, contentDigest, &result.signingTime)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	rsaPub, ok := result.signerCert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return result, fmt.Errorf("unsupported signer public key type %T (only RSA is supported)", result.signerCert.PublicKey)
+	}
+	if err := rsa.VerifyPKCS1v15(rsaPub, hashAlg, signedBytes, signer.EncryptedDigest); err != nil {
+		return result, fmt.Errorf("signature does not verify against the embedded certificate: %w", err)
+	}
+
+	return result, nil
+}
+
+// digestOverAuthenticatedAttributes reads the messageDigest and
+// (if present) signingTime authenticated attributes, confirms messageDigest
+// matches contentDigest, and returns the digest actually signed: per RFC
+// 5652 5.4, that's the hash of the attribute SET re-encoded with a
+// universal SET tag rather than the [0] IMPLICIT tag it's stored under.
+func digestOverAuthenticatedAttributes(attrs asn1.RawValue, hashAlg crypto.Hash, contentDigest []byte, signingTime *string) ([]byte, error) {
+	attrRaws, err := splitRawElements(attrs.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing authenticated attributes: %w", err)
+	}
+
+	var messageDigest []byte
+	for _, raw := range attrRaws {
+		var attr cmsAttribute
+		if _, err := asn1.Unmarshal(raw.FullBytes, &attr); err != nil {
+			continue
+		}
+		value, err := splitRawElements(attr.Values.Bytes)
+		if err != nil || len(value) == 0 {
+			continue
+		}
+		switch {
+		case attr.Type.Equal(oidMessageDigest):
+			asn1.Unmarshal(value[0].FullBytes, &messageDigest)
+		case attr.Type.Equal(oidSigningTime):
+			var t time.Time
+			if _, err := asn1.Unmarshal(value[0].FullBytes, &t); err == nil {
+				*signingTime = t.UTC().Format(time.RFC3339)
+			}
+		}
+	}
+	if !bytes.Equal(messageDigest, contentDigest) {
+		return nil, fmt.Errorf("signed messageDigest attribute does not match the /ByteRange content digest")
+	}
+
+	reencoded := append([]byte(nil), attrs.FullBytes...)
+	reencoded[0] = 0x31 // SET, universal, constructed
+	h := hashAlg.New()
+	h.Write(reencoded)
+	return h.Sum(nil), nil
+}
+
+// splitRawElements decodes the concatenated, header-included TLV elements
+// held in the content bytes of a SET/SEQUENCE (asn1.RawValue.Bytes), which
+// Go's asn1 package otherwise leaves opaque.
+func splitRawElements(content []byte) ([]asn1.RawValue, error) {
+	var out []asn1.RawValue
+	rest := content
+	for len(rest) > 0 {
+		var rv asn1.RawValue
+		next, err := asn1.Unmarshal(rest, &rv)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rv)
+		rest = next
+	}
+	return out, nil
+}
+
+// certMatchesIssuerSerial reports whether cert is the certificate named by
+// a SignerInfo's IssuerAndSerialNumber.
+func certMatchesIssuerSerial(cert *x509.Certificate, ref issuerAndSerial) bool {
+	if !bytes.Equal(cert.RawIssuer, ref.Issuer.FullBytes) {
+		return false
+	}
+	// encoding/asn1 only special-cases *big.Int (see bigIntType in the
+	// standard library): unmarshaling into a plain big.Int falls through to
+	// the generic struct path and expects a SEQUENCE, not an INTEGER. The
+	// target must be declared as *big.Int for Unmarshal to parse it as one.
+	var serial *big.Int
+	if _, err := asn1.Unmarshal(ref.SerialNumber.FullBytes, &serial); err != nil {
+		return false
+	}
+	return cert.SerialNumber.Cmp(serial) == 0
+}
+
+// hashForOID maps a CMS digest algorithm OID to the crypto.Hash this
+// package knows how to compute.
+func hashForOID(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, true
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, true
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, true
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// UpdateOptions controls how AppendRevision lays out the appended revision.
+type UpdateOptions struct {
+	// XRefStream, when true, emits a PDF 1.5 cross-reference stream for the
+	// new revision (matching how this package already reads them). When
+	// false, a classic xref table + trailer is emitted instead, for target
+	// files whose existing revisions are all classic.
+	XRefStream bool
+}
+
+// AppendRevision produces an *incremental update*: the original file bytes
+// untouched, followed by newObjects (keyed by object number) and a fresh
+// xref section/trailer whose /Prev points at the file's previous startxref.
+// This is how PDF viewers and signing tools add a revision without
+// disturbing byte offsets any existing signature already covers.
+func (r *Reader) AppendRevision(newObjects map[int]Object, opts UpdateOptions) ([]byte, error) {
+	if len(newObjects) == 0 {
+		return nil, fmt.Errorf("AppendRevision: no objects to append")
+	}
+
+	original := make([]byte, r.size)
+	if _, err := io.ReadFull(r.sectionFrom(0), original); err != nil {
+		return nil, fmt.Errorf("failed to read original file bytes: %w", err)
+	}
+
+	prevStartXRef, err := findStartXRef(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate previous startxref: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(original)
+	if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	objNums := make([]int, 0, len(newObjects))
+	maxObjNum := 0
+	for num := range newObjects {
+		objNums = append(objNums, num)
+		if num > maxObjNum {
+			maxObjNum = num
+		}
+	}
+	sort.Ints(objNums)
+
+	offsets := make(map[int]int64, len(objNums))
+	for _, num := range objNums {
+		offsets[num] = int64(buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", num)
+		if err := writeObject(&buf, newObjects[num]); err != nil {
+			return nil, fmt.Errorf("failed to serialize object %d: %w", num, err)
+		}
+		buf.WriteString("\nendobj\n")
+	}
+
+	size := maxObjNum + 1
+	if existingSize, ok := r.xref.Trailer["/Size"].(NumberObject); ok && int(existingSize) > size {
+		size = int(existingSize)
+	}
+
+	newID := nextRevisionID(r.xref.Trailer, original)
+
+	xrefOffset := int64(buf.Len())
+	if opts.XRefStream {
+		if err := writeXRefStreamRevision(&buf, r.xref.Trailer, objNums, offsets, size, prevStartXRef, newID); err != nil {
+			return nil, err
+		}
+	} else {
+		writeClassicXRefRevision(&buf, r.xref.Trailer, objNums, offsets, size, prevStartXRef, newID)
+	}
+
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+	return buf.Bytes(), nil
+}
+
+// writeObject serializes obj as it would appear inline in a PDF body. Every
+// concrete Object already knows how to render itself via String() (used
+// elsewhere for things like comparing /Type), except StreamObject, which
+// additionally carries its raw (re-encoded by the caller, if needed) bytes.
+func writeObject(w *bytes.Buffer, obj Object) error {
+	if stm, ok := obj.(StreamObject); ok {
+		w.WriteString(stm.Dictionary.String())
+		w.WriteString("\nstream\n")
+		w.Write(stm.Data)
+		w.WriteString("\nendstream")
+		return nil
+	}
+	if obj == nil {
+		w.WriteString("null")
+		return nil
+	}
+	w.WriteString(obj.String())
+	return nil
+}
+
+// nextRevisionID derives the second element of the trailer's /ID pair for
+// the new revision: the first element is carried over unchanged (readers
+// use it to recognize "the same logical document" across revisions), and
+// the second is refreshed so each revision is independently identifiable,
+// per the PDF spec's recommendation to change it on every save.
+func nextRevisionID(trailer DictionaryObject, original []byte) ArrayObject {
+	firstSum := md5.Sum(original)
+	first := HexStringObject(firstSum[:])
+	if idArr, ok := trailer["/ID"].(ArrayObject); ok && len(idArr) > 0 {
+		switch v := idArr[0].(type) {
+		case HexStringObject:
+			first = v
+		case StringObject:
+			first = HexStringObject(v)
+		}
+	}
+
+	h := md5.New()
+	h.Write(original)
+	h.Write([]byte(fmt.Sprintf("%d", time.Now().UnixNano())))
+	second := HexStringObject(h.Sum(nil))
+
+	return ArrayObject{first, second}
+}
+
+// writeClassicXRefRevision emits a classic "xref ... trailer" section
+// covering only the objects changed in this revision, chained to the prior
+// revision via /Prev.
+func writeClassicXRefRevision(buf *bytes.Buffer, prevTrailer DictionaryObject, objNums []int, offsets map[int]int64, size int, prevStartXRef int64, id ArrayObject) {
+	buf.WriteString("xref\n")
+
+	// Group contiguous object numbers into subsections, same as a classic
+	// xref table would, even though we're only listing the new/changed ones.
+	for i := 0; i < len(objNums); {
+		start := objNums[i]
+		j := i
+		for j+1 < len(objNums) && objNums[j+1] == objNums[j]+1 {
+			j++
+		}
+		count := j - i + 1
+		fmt.Fprintf(buf, "%d %d\n", start, count)
+		for k := i; k <= j; k++ {
+			fmt.Fprintf(buf, "%010d %05d n \n", offsets[objNums[k]], 0)
+		}
+		i = j + 1
+	}
+
+	trailer := DictionaryObject{
+		"/Size": NumberObject(size),
+		"/Root": prevTrailer["/Root"],
+		"/Prev": NumberObject(prevStartXRef),
+		"/ID":   id,
+	}
+	if info, ok := prevTrailer["/Info"]; ok {
+		trailer["/Info"] = info
+	}
+
+	buf.WriteString("trailer\n")
+	buf.WriteString(trailer.String())
+	buf.WriteByte('\n')
+}
+
+// writeXRefStreamRevision emits a PDF 1.5 XRef stream (type 2 cross
+// reference) for the new revision, using the same /W [1 4 2] layout and
+// uncompressed (no /Filter) encoding to keep the writer simple — readers
+// are required to support an unfiltered XRef stream just as well as a
+// FlateDecode'd one.
+func writeXRefStreamRevision(buf *bytes.Buffer, prevTrailer DictionaryObject, objNums []int, offsets map[int]int64, size int, prevStartXRef int64, id ArrayObject) error {
+	xrefObjNum := size
+	size = xrefObjNum + 1
+
+	var rows bytes.Buffer
+	var index ArrayObject
+	for i := 0; i < len(objNums); {
+		start := objNums[i]
+		j := i
+		for j+1 < len(objNums) && objNums[j+1] == objNums[j]+1 {
+			j++
+		}
+		index = append(index, NumberObject(start), NumberObject(j-i+1))
+		for k := i; k <= j; k++ {
+			off := offsets[objNums[k]]
+			rows.WriteByte(1) // type 1: in use, uncompressed
+			rows.Write([]byte{byte(off >> 24), byte(off >> 16), byte(off >> 8), byte(off)})
+			rows.Write([]byte{0, 0}) // generation 0
+		}
+		i = j + 1
+	}
+
+	dict := DictionaryObject{
+		"/Type":   NameObject("/XRef"),
+		"/Size":   NumberObject(size),
+		"/W":      ArrayObject{NumberObject(1), NumberObject(4), NumberObject(2)},
+		"/Index":  index,
+		"/Root":   prevTrailer["/Root"],
+		"/Prev":   NumberObject(prevStartXRef),
+		"/ID":     id,
+		"/Length": NumberObject(rows.Len()),
+	}
+	if info, ok := prevTrailer["/Info"]; ok {
+		dict["/Info"] = info
+	}
+
+	fmt.Fprintf(buf, "%d 0 obj\n", xrefObjNum)
+	buf.WriteString(dict.String())
+	buf.WriteString("\nstream\n")
+	buf.Write(rows.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
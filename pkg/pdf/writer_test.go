@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"testing"
+)
+
+// TestNextRevisionIDHashesOriginal regression-tests a bug where the second
+// /ID element was built from md5.New().Sum(original) - which appends
+// MD5("") to original instead of hashing it, since a freshly constructed
+// hash.Hash has nothing written to it yet - so the "hash" was actually the
+// entire original file followed by 16 garbage bytes. Both elements must be
+// real 16-byte MD5 digests.
+func TestNextRevisionIDHashesOriginal(t *testing.T) {
+	original := []byte("the original file bytes, pretend this is a whole PDF")
+
+	id := nextRevisionID(DictionaryObject{}, original)
+	if len(id) != 2 {
+		t.Fatalf("nextRevisionID returned %d elements, want 2", len(id))
+	}
+
+	first, ok := id[0].(HexStringObject)
+	if !ok {
+		t.Fatalf("id[0] = %T, want HexStringObject", id[0])
+	}
+	if len(first) != md5.Size {
+		t.Fatalf("len(first) = %d, want %d (a bare MD5 digest, not original's bytes appended to one)", len(first), md5.Size)
+	}
+	wantFirst := md5.Sum(original)
+	if !bytes.Equal(first, wantFirst[:]) {
+		t.Fatalf("first = %x, want MD5(original) = %x", first, wantFirst)
+	}
+
+	second, ok := id[1].(HexStringObject)
+	if !ok {
+		t.Fatalf("id[1] = %T, want HexStringObject", id[1])
+	}
+	if len(second) != md5.Size {
+		t.Fatalf("len(second) = %d, want %d", len(second), md5.Size)
+	}
+}
+
+// TestNextRevisionIDCarriesOverExistingFirstElement confirms the trailer's
+// existing /ID[0] is preserved unchanged (readers rely on it to recognize
+// "the same logical document" across revisions) rather than being
+// recomputed from the new revision's bytes.
+func TestNextRevisionIDCarriesOverExistingFirstElement(t *testing.T) {
+	existing := HexStringObject([]byte("0123456789abcdef"))
+	trailer := DictionaryObject{"/ID": ArrayObject{existing, HexStringObject([]byte("fedcba9876543210"))}}
+
+	id := nextRevisionID(trailer, []byte("new revision bytes"))
+	if first, ok := id[0].(HexStringObject); !ok || !bytes.Equal(first, existing) {
+		t.Fatalf("id[0] = %v, want the trailer's existing /ID[0] %v unchanged", id[0], existing)
+	}
+}
+
+// TestAppendRevisionTrailerIDIsWellFormed builds a minimal single-object PDF
+// with no /ID in its trailer (the common case, since /ID is optional) and
+// confirms AppendRevision's new trailer carries a 16-byte-per-element /ID
+// array rather than embedding the whole original file as a hex string.
+func TestAppendRevisionTrailerIDIsWellFormed(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	objOffset := buf.Len()
+	buf.WriteString("1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 2\n0000000000 65535 f \n%010d 00000 n \n", objOffset)
+	fmt.Fprintf(&buf, "trailer\n<< /Size 2 /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF", xrefOffset)
+
+	original := buf.Bytes()
+	r, err := NewReader(bytes.NewReader(original), int64(len(original)))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	updated, err := r.AppendRevision(map[int]Object{2: DictionaryObject{"/Type": NameObject("/Pages")}}, UpdateOptions{})
+	if err != nil {
+		t.Fatalf("AppendRevision: %v", err)
+	}
+
+	r2, err := NewReader(bytes.NewReader(updated), int64(len(updated)))
+	if err != nil {
+		t.Fatalf("NewReader on the updated revision: %v", err)
+	}
+	idArr, ok := r2.xref.Trailer["/ID"].(ArrayObject)
+	if !ok || len(idArr) != 2 {
+		t.Fatalf("updated trailer /ID = %#v, want a 2-element array", r2.xref.Trailer["/ID"])
+	}
+	for i, elem := range idArr {
+		hex, ok := elem.(HexStringObject)
+		if !ok || len(hex) != md5.Size {
+			t.Fatalf("id[%d] = %#v, want a %d-byte HexStringObject", i, elem, md5.Size)
+		}
+	}
+}
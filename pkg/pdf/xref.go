@@ -2,7 +2,6 @@ package pdf
 
 import (
 	"bytes"
-	"compress/zlib"
 	"errors"
 	"fmt"
 	"io"
@@ -69,10 +68,28 @@ func ParseXRef(rs io.ReadSeeker) (*XRefTable, error) {
 		var tr DictionaryObject
 
 		if string(sig[:4]) == "xref" {
-			prevOffset, tr, err = table.readStandardXRef(rs)
+			classic := NewXRefTable()
+			prevOffset, tr, err = classic.readStandardXRef(rs)
 			if err != nil {
 				return nil, fmt.Errorf("readStandardXRef failed: %w", err)
 			}
+
+			// Hybrid-reference file (PDF 1.5+ writer keeping a classic table
+			// for pre-1.5 readers): the trailer's /XRefStm points at a
+			// supplemental xref stream carrying entries a classic table can't
+			// express, namely compressed objects living in /ObjStm
+			// containers. Those objects are necessarily listed as free in
+			// the classic table just read, so fold the stream's entries into
+			// table BEFORE the classic ones: insert-if-not-exists then
+			// naturally lets the stream's real entry win over the classic
+			// placeholder for the same id, while still leaving alone
+			// anything an already-processed (newer) revision supplied.
+			if stmOffset, ok := tr["/XRefStm"].(NumberObject); ok {
+				if err := table.mergeXRefStm(rs, int64(stmOffset)); err != nil {
+					return nil, fmt.Errorf("failed to merge hybrid /XRefStm at %d: %w", int64(stmOffset), err)
+				}
+			}
+			table.mergeFrom(classic)
 		} else {
 			prevOffset, tr, err = table.readXRefStream(rs)
 			if err != nil {
@@ -259,15 +276,19 @@ func (t *XRefTable) readXRefStream(rs io.ReadSeeker) (int64, DictionaryObject, e
 	if !ok || len(wArr) != 3 {
 		return 0, nil, errors.New("invalid /W array")
 	}
-	w := []int{int(wArr[0].(NumberObject)), int(wArr[1].(NumberObject)), int(wArr[2].(NumberObject))}
+	w, ok := intsFromArray(wArr)
+	if !ok {
+		return 0, nil, errors.New("invalid /W array: entries must be direct integers")
+	}
 	stride := w[0] + w[1] + w[2]
 
 	// /Index [ 0 12 ] -> Start, Count (pairs)
 	// Default is [0 Size]
 	var index []int
 	if idxObj, ok := streamDict["/Index"].(ArrayObject); ok {
-		for _, v := range idxObj {
-			index = append(index, int(v.(NumberObject)))
+		index, ok = intsFromArray(idxObj)
+		if !ok {
+			return 0, nil, errors.New("invalid /Index array: entries must be direct integers")
 		}
 	} else {
 		if sizeObj, ok := streamDict["/Size"].(NumberObject); ok {
@@ -290,46 +311,31 @@ func (t *XRefTable) readXRefStream(rs io.ReadSeeker) (int64, DictionaryObject, e
 		return 0, nil, fmt.Errorf("failed to read compressed stream data: %w", err)
 	}
 
-	zr, err := zlib.NewReader(bytes.NewReader(compressedData))
-	if err != nil {
-		return 0, nil, err
-	}
-	defer zr.Close()
-	decoded, err := io.ReadAll(zr)
-	if err != nil {
-		return 0, nil, err
-	}
-
-	// 4. Apply Predictor (PNG Up) if needed
-	// PDF uses Predictor 12 (PNG Up) commonly for XRef streams
-	predictor := 1
-	columns := 1
-	if params, ok := streamDict["/DecodeParms"].(DictionaryObject); ok {
-		if p, ok := params["/Predictor"].(NumberObject); ok {
-			predictor = int(p)
-		}
-		if c, ok := params["/Columns"].(NumberObject); ok {
-			columns = int(c)
-		} else {
-			columns = 1 // Default usually 1 for XRef? Actually it's 'stride' conceptually
+	// /DecodeParms usually omits /Columns for XRef streams; the spec says it
+	// defaults to the sum of /W's widths, not 1, so fill that in before
+	// handing off to the shared predictor logic in DecodeChain.
+	parms, _ := streamDict["/DecodeParms"].(DictionaryObject)
+	if parms != nil {
+		if _, hasColumns := parms["/Columns"]; !hasColumns {
+			if _, hasPredictor := parms["/Predictor"]; hasPredictor {
+				patched := make(DictionaryObject, len(parms)+1)
+				for k, v := range parms {
+					patched[k] = v
+				}
+				patched["/Columns"] = NumberObject(stride)
+				parms = patched
+			}
 		}
 	}
 
-	// If Predictor >= 10, data is PNG encoded.
-	// Rows are (columns + 1) bytes wide (1 byte filter tag)
-	if predictor >= 10 {
-		// Re-calculate stride if columns wasn't set explicitly to match W sum?
-		// Actually for XRef, 'Columns' usually isn't set, the row width is sum(W).
-		// The PDF spec says for XRef streams: "The columns parameter... defaults to the sum of items in W"
-		if columns == 1 && stride > 1 {
-			columns = stride
-		}
-
-		var err error
-		decoded, err = applyPngPredictor(decoded, columns, predictor)
-		if err != nil {
-			return 0, nil, err
-		}
+	// /Filter is almost always a bare /FlateDecode, but nothing in the spec
+	// rules out the writer prefixing it with an ASCII filter; decode via the
+	// same /Filter chain every other stream uses rather than assuming. An
+	// xref stream with no /Filter (as writeXRefStreamRevision emits) is read
+	// as stored.
+	decoded, err := DecodeChain(compressedData, streamDict["/Filter"], parms)
+	if err != nil {
+		return 0, nil, fmt.Errorf("decoding xref stream: %w", err)
 	}
 
 	// 5. Parse entries
@@ -349,13 +355,18 @@ func (t *XRefTable) readXRefStream(rs io.ReadSeeker) (int64, DictionaryObject, e
 				// Type 0: Free (f1=0, f2=nextGen, f3=gen?? spec says f2=objNum of next free)
 				// Type 1: InUse (f1=1, f2=offset, f3=gen)
 				// Type 2: Compressed (f1=2, f2=streamObjNum, f3=index)
-
-				switch f1 {
-				case 1: // In Use
+				//
+				// /W's first width is allowed to be 0, in which case the type
+				// field isn't stored at all and every entry defaults to type
+				// 1 (readField already returns 0 for a zero-width field, so
+				// without this case every entry in such a stream would be
+				// misread as free).
+				switch {
+				case w[0] == 0, f1 == 1: // In Use
 					t.Entries[id] = XRefEntry{Offset: f2, Generation: int(f3), Free: false}
-				case 2: // Compressed
+				case f1 == 2: // Compressed
 					t.Entries[id] = XRefEntry{Compressed: true, StreamObj: int(f2), StreamIdx: int(f3), Free: false}
-				case 0: // Free
+				case f1 == 0: // Free
 					t.Entries[id] = XRefEntry{Free: true, Generation: int(f3)}
 				}
 			}
@@ -369,6 +380,56 @@ func (t *XRefTable) readXRefStream(rs io.ReadSeeker) (int64, DictionaryObject, e
 	return prev, streamDict, nil
 }
 
+// mergeXRefStm parses the xref stream at offset (a hybrid file's /XRefStm)
+// and folds its entries into t, without following its own /Prev — the
+// classic chain already being walked by ParseXRef is the authoritative
+// revision history. It's always called before t has seen this revision's
+// classic table (see ParseXRef), so the plain insert-if-not-exists rule in
+// mergeFrom is enough to let the stream's entry win over the classic
+// placeholder that the same object id gets there, without disturbing
+// anything an already-processed, newer revision supplied.
+func (t *XRefTable) mergeXRefStm(rs io.ReadSeeker, offset int64) error {
+	if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to /XRefStm at %d: %w", offset, err)
+	}
+	stm := NewXRefTable()
+	if _, _, err := stm.readXRefStream(rs); err != nil {
+		return err
+	}
+	t.mergeFrom(stm)
+	return nil
+}
+
+// mergeFrom folds other's entries into t, keeping t's existing entry for any
+// id both define. Entries are always populated newest-revision-first, so
+// "already present in t" means a newer revision (or, within one hybrid
+// revision, the /XRefStm) already supplied the authoritative state for that
+// id.
+func (t *XRefTable) mergeFrom(other *XRefTable) {
+	for id, entry := range other.Entries {
+		if _, exists := t.Entries[id]; !exists {
+			t.Entries[id] = entry
+		}
+	}
+}
+
+// intsFromArray converts every entry of arr to an int, failing if any entry
+// isn't a direct NumberObject. /W, /Index and similar xref-stream fields are
+// read before any object can be resolved, so a malformed file that puts an
+// indirect reference or a non-numeric value there must be reported, not
+// panic the type assertion.
+func intsFromArray(arr ArrayObject) ([]int, bool) {
+	out := make([]int, 0, len(arr))
+	for _, v := range arr {
+		n, ok := v.(NumberObject)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, int(n))
+	}
+	return out, true
+}
+
 // readField reads `width` bytes as a big-endian integer
 func readField(r io.Reader, width int) int64 {
 	if width == 0 {
@@ -384,92 +445,3 @@ func readField(r io.Reader, width int) int64 {
 	return res
 }
 
-// applyPngPredictor decodes PNG predicted data (Predictor >= 10)
-// Simplified for PNG Up (12) which is most common in PDFs.
-func applyPngPredictor(data []byte, columns int, predictor int) ([]byte, error) {
-	// Validate predictor is in PNG range (10-15 per PDF spec)
-	if predictor < 10 || predictor > 15 {
-		return nil, fmt.Errorf("unsupported predictor: %d (expected 10-15)", predictor)
-	}
-
-	// Row size = columns + 1 (filter byte)
-	rowSize := columns + 1
-	if len(data)%rowSize != 0 {
-		// It might be loose, but let's warn/ignore
-	}
-
-	rowCount := len(data) / rowSize
-	out := make([]byte, rowCount*columns)
-
-	// Previous row buffer (initially zero)
-	prevRow := make([]byte, columns)
-
-	for i := 0; i < rowCount; i++ {
-		rowStart := i * rowSize
-		filter := data[rowStart]
-		rowBytes := data[rowStart+1 : rowStart+rowSize]
-
-		// Target slice in output
-		outStart := i * columns
-		outRow := out[outStart : outStart+columns]
-
-		switch filter {
-		case 0: // None
-			copy(outRow, rowBytes)
-		case 1: // Sub (Left)
-			var left byte = 0
-			for x := 0; x < columns; x++ {
-				val := rowBytes[x] + left
-				outRow[x] = val
-				left = val
-			}
-		case 2: // Up
-			for x := 0; x < columns; x++ {
-				outRow[x] = rowBytes[x] + prevRow[x]
-			}
-		case 3: // Average
-			var left byte = 0
-			for x := 0; x < columns; x++ {
-				avg := (int(left) + int(prevRow[x])) / 2
-				val := byte(int(rowBytes[x]) + avg)
-				outRow[x] = val
-				left = val
-			}
-		case 4: // Paeth
-			var left byte = 0
-			var upperLeft byte = 0
-			for x := 0; x < columns; x++ {
-				upper := prevRow[x]
-				val := byte(int(rowBytes[x]) + paethPredictor(int(left), int(upper), int(upperLeft)))
-				outRow[x] = val
-				left = val
-				upperLeft = upper
-			}
-		default: // Fallback treat as None
-			copy(outRow, rowBytes)
-		}
-
-		copy(prevRow, outRow)
-	}
-	return out, nil
-}
-
-func paethPredictor(a, b, c int) int {
-	p := a + b - c
-	pa := abs(p - a)
-	pb := abs(p - b)
-	pc := abs(p - c)
-	if pa <= pb && pa <= pc {
-		return a
-	} else if pb <= pc {
-		return b
-	}
-	return c
-}
-
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
@@ -0,0 +1,59 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+)
+
+// TestParseXRefHybridXRefStmWins builds a minimal hybrid-reference PDF where
+// object 3 is listed as a free entry in the classic xref table (the only way
+// a pre-1.5 reader can represent a compressed object) but as a real
+// compressed entry in the trailer's /XRefStm stream. ParseXRef must resolve
+// object 3 through the stream's entry, not the classic free placeholder -
+// regression test for mergeXRefStm dropping the stream's entry in favor of
+// whichever the classic table (read first) already inserted.
+func TestParseXRefHybridXRefStmWins(t *testing.T) {
+	// Row for object 3 under /W [1 2 1]: type 2 (compressed), stream obj 7,
+	// index 0 within that stream's /ObjStm.
+	row := []byte{2, 0, 7, 0}
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(row); err != nil {
+		t.Fatalf("compressing xref stream row: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zlib writer: %v", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+
+	xrefStmOffset := buf.Len()
+	fmt.Fprintf(&buf, "10 0 obj\n<< /Type /XRef /Filter /FlateDecode /W [1 2 1] /Index [3 1] /Size 11 /Length %d >>\nstream\n", compressed.Len())
+	buf.Write(compressed.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+
+	classicOffset := buf.Len()
+	buf.WriteString("xref\n3 1\n0000000000 00000 f \n")
+	fmt.Fprintf(&buf, "trailer\n<< /Size 4 /Root 1 0 R /XRefStm %d >>\n", xrefStmOffset)
+
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF", classicOffset)
+
+	table, err := ParseXRef(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ParseXRef: %v", err)
+	}
+
+	entry, ok := table.Entries[3]
+	if !ok {
+		t.Fatalf("object 3 missing from xref table")
+	}
+	if entry.Free {
+		t.Fatalf("object 3 resolved via the classic free entry, not the /XRefStm compressed entry: %+v", entry)
+	}
+	if !entry.Compressed || entry.StreamObj != 7 || entry.StreamIdx != 0 {
+		t.Fatalf("object 3 entry = %+v, want Compressed StreamObj=7 StreamIdx=0", entry)
+	}
+}